@@ -0,0 +1,67 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"image-to-ascii-art/lib"
+	"sync"
+	"syscall/js"
+	"unsafe"
+)
+
+var (
+	buffersMu  sync.Mutex
+	buffers    = make(map[int][]byte)
+	nextBuffer = 1
+)
+
+func allocInputBuffer(this js.Value, args []js.Value) any {
+	if len(args) != 1 || args[0].Type() != js.TypeNumber {
+		lib.Logf(lib.LevelError, "allocInputBufferGo expects a byte length")
+		return js.Null()
+	}
+	size := args[0].Int()
+	if maxImageSize := lib.CurrentLimits().MaxImageSize; size <= 0 || size > maxImageSize {
+		lib.Logf(lib.LevelError, "input buffer size must be between 1 and %d bytes, got %d", maxImageSize, size)
+		return js.Null()
+	}
+
+	buffer := make([]byte, size)
+	buffersMu.Lock()
+	defer buffersMu.Unlock()
+	handle := nextBuffer
+	nextBuffer++
+	buffers[handle] = buffer
+
+	return js.ValueOf(map[string]any{
+		"handle":     handle,
+		"byteOffset": int(uintptr(unsafe.Pointer(&buffer[0]))),
+		"length":     size,
+	})
+}
+
+func releaseInputBuffer(this js.Value, args []js.Value) any {
+	if len(args) != 1 || args[0].Type() != js.TypeNumber {
+		return false
+	}
+
+	buffersMu.Lock()
+	defer buffersMu.Unlock()
+	handle := args[0].Int()
+	if _, ok := buffers[handle]; !ok {
+		return false
+	}
+	delete(buffers, handle)
+	return true
+}
+
+func lookupInputBuffer(handleJS js.Value) ([]byte, error) {
+	buffersMu.Lock()
+	defer buffersMu.Unlock()
+	handle := handleJS.Int()
+	buffer, ok := buffers[handle]
+	if !ok {
+		return nil, lib.NewError(lib.CodeBadInput, "unknown or released input buffer handle: %d", handle)
+	}
+	return buffer, nil
+}
@@ -0,0 +1,46 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"image-to-ascii-art/lib"
+	"syscall/js"
+)
+
+var inputTypes = []string{"Uint8Array", "base64", "dataURL", "ImageBitmap", "OffscreenCanvas", "inputBuffer"}
+
+func getCapabilities(this js.Value, args []js.Value) any {
+	defaults := lib.DefaultOptions()
+	limits := lib.CurrentLimits()
+	options := make(map[string]any, len(optionFields))
+	for name, field := range optionFields {
+		option := map[string]any{"type": field.kind.String()}
+		if field.value != nil {
+			option["default"] = field.value(defaults)
+		}
+		if min, max := field.bounds(limits.MaxASCIIDimension); min != 0 || max != 0 {
+			option["min"] = min
+			option["max"] = max
+		}
+		if field.values != nil {
+			option["values"] = stringsToJS(field.values)
+		}
+		options[name] = option
+	}
+
+	return js.ValueOf(map[string]any{
+		"inputFormats":  stringsToJS(lib.InputFormats),
+		"inputTypes":    stringsToJS(inputTypes),
+		"outputFormats": stringsToJS(lib.OutputFormats),
+		"options":       options,
+		"limits":        limitsToJS(limits),
+	})
+}
+
+func stringsToJS(values []string) []any {
+	result := make([]any, len(values))
+	for i, v := range values {
+		result[i] = v
+	}
+	return result
+}
@@ -0,0 +1,45 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"encoding/binary"
+	"fmt"
+	"image-to-ascii-art/lib"
+	"math"
+	"sync"
+	"syscall/js"
+)
+
+var selectCharsJS = sync.OnceValue(func() js.Value {
+	return js.Global().Get("Function").New("select", "samples", `
+		let out = "";
+		for (let i = 0; i < samples.length; i += 4) {
+			const code = String(select(samples[i], samples[i + 1], samples[i + 2], samples[i + 3]) ?? "").codePointAt(0);
+			out += code === undefined ? " " : String.fromCodePoint(code);
+		}
+		return out;
+	`)
+})
+
+func jsCharSelector(fn js.Value) lib.CharSelectFunc {
+	return func(samples []lib.CharSample) (chars []rune, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("%v", r)
+			}
+		}()
+
+		buf := make([]byte, len(samples)*16)
+		for i, sample := range samples {
+			values := [4]float32{float32(sample.Luminance), float32(sample.R), float32(sample.G), float32(sample.B)}
+			for j, v := range values {
+				binary.LittleEndian.PutUint32(buf[i*16+j*4:], math.Float32bits(v))
+			}
+		}
+		bytes := js.Global().Get("Uint8Array").New(len(buf))
+		js.CopyBytesToJS(bytes, buf)
+		floats := js.Global().Get("Float32Array").New(bytes.Get("buffer"))
+		return []rune(selectCharsJS().Invoke(fn, floats).String()), nil
+	}
+}
@@ -0,0 +1,110 @@
+//go:build !js
+
+package main
+
+import (
+	"flag"
+	"image-to-ascii-art/lib"
+	"strconv"
+	"strings"
+)
+
+func registerOptionFlags(flags *flag.FlagSet, opts *lib.Options) {
+	flags.IntVar(&opts.TargetWidth, "targetWidth", opts.TargetWidth, "target width")
+	flags.IntVar(&opts.OutputWidth, "outputWidth", opts.OutputWidth, "output width in pixels (overrides targetWidth)")
+	flags.IntVar(&opts.TargetHeight, "targetHeight", opts.TargetHeight, "target height")
+	flags.StringVar(&opts.Fit, "fit", opts.Fit, "fit ("+strings.Join(lib.FitNames(), ", ")+")")
+	flags.StringVar(&opts.Preset, "preset", opts.Preset, "preset ("+strings.Join(lib.PresetNames(), ", ")+")")
+	flags.StringVar(&opts.Theme, "theme", opts.Theme, "theme ("+strings.Join(lib.ThemeNames(), ", ")+")")
+	flags.IntVar(&opts.MaxProcessDimension, "maxProcessDimension", opts.MaxProcessDimension, "max process dimension")
+	flags.IntVar(&opts.MaxASCIIDimension, "maxASCIIDimension", opts.MaxASCIIDimension, "max ASCII dimension (0 for the configured limit)")
+	flags.StringVar(&opts.Resample, "resample", opts.Resample, "resample ("+strings.Join(lib.ResampleNames(), ", ")+")")
+	flags.Float64Var(&opts.Brightness, "brightness", opts.Brightness, "brightness")
+	flags.Float64Var(&opts.Contrast, "contrast", opts.Contrast, "contrast")
+	flags.Float64Var(&opts.Sharpen, "sharpen", opts.Sharpen, "sharpen")
+	flags.StringVar(&opts.BackgroundColor, "backgroundColor", opts.BackgroundColor, "background color")
+	flags.StringVar(&opts.TransparencyColor, "transparencyColor", opts.TransparencyColor, "transparency color")
+	flags.Float64Var(&opts.TransparencyThreshold, "transparencyThreshold", opts.TransparencyThreshold, "transparency threshold")
+	flags.StringVar(&opts.ChromaKey, "chromaKey", opts.ChromaKey, "chroma key color")
+	flags.Float64Var(&opts.ChromaKeyTolerance, "chromaKeyTolerance", opts.ChromaKeyTolerance, "chroma key tolerance")
+	flags.StringVar(&opts.Charset, "charset", opts.Charset, "charset ("+strings.Join(lib.CharsetNames(), ", ")+")")
+	flags.StringVar(&opts.Mode, "mode", opts.Mode, "mode ("+strings.Join(lib.ModeNames(), ", ")+")")
+	flags.StringVar(&opts.Dither, "dither", opts.Dither, "dither ("+strings.Join(lib.DitherNames(), ", ")+")")
+	flags.Float64Var(&opts.Threshold, "threshold", opts.Threshold, "threshold (0 for automatic)")
+	flags.BoolVar(&opts.Grayscale, "grayscale", opts.Grayscale, "grayscale")
+	flags.StringVar(&opts.LuminanceFormula, "luminanceFormula", opts.LuminanceFormula, "luminance formula ("+strings.Join(lib.LuminanceFormulaNames(), ", ")+")")
+	flags.StringVar(&opts.MonochromeColor, "monochromeColor", opts.MonochromeColor, "monochrome color")
+	flags.BoolVar(&opts.Invert, "invert", opts.Invert, "invert")
+	flags.BoolVar(&opts.LinearLight, "linearLight", opts.LinearLight, "linear light")
+	flags.Float64Var(&opts.HueShift, "hueShift", opts.HueShift, "hue shift")
+	flags.Float64Var(&opts.Grain, "grain", opts.Grain, "film grain amount")
+	flags.Int64Var(&opts.GrainSeed, "grainSeed", opts.GrainSeed, "film grain seed")
+	flags.Func("toneCurve", "comma-separated tone curve points between 0 and 1", func(value string) error {
+		opts.ToneCurve = nil
+		for _, field := range strings.Split(value, ",") {
+			point, err := strconv.ParseFloat(strings.TrimSpace(field), 64)
+			if err != nil {
+				return err
+			}
+			opts.ToneCurve = append(opts.ToneCurve, point)
+		}
+		return nil
+	})
+	flags.StringVar(&opts.DuotoneShadow, "duotoneShadow", opts.DuotoneShadow, "duotone shadow")
+	flags.StringVar(&opts.DuotoneHighlight, "duotoneHighlight", opts.DuotoneHighlight, "duotone highlight")
+	flags.BoolVar(&opts.AutoContrast, "autoContrast", opts.AutoContrast, "auto contrast")
+	flags.BoolVar(&opts.AutoLevels, "autoLevels", opts.AutoLevels, "auto levels")
+	flags.Float64Var(&opts.AutoLevelsClip, "autoLevelsClip", opts.AutoLevelsClip, "auto levels clip percentile")
+	flags.Float64Var(&opts.ClaheClipLimit, "claheClipLimit", opts.ClaheClipLimit, "clahe clip limit")
+	flags.IntVar(&opts.ClaheTiles, "claheTiles", opts.ClaheTiles, "clahe tiles")
+	flags.Float64Var(&opts.Blur, "blur", opts.Blur, "blur")
+	flags.IntVar(&opts.MedianRadius, "medianRadius", opts.MedianRadius, "median radius")
+	flags.IntVar(&opts.Pixelate, "pixelate", opts.Pixelate, "pixelate block size")
+	flags.Float64Var(&opts.UnsharpRadius, "unsharpRadius", opts.UnsharpRadius, "unsharp radius")
+	flags.Float64Var(&opts.UnsharpAmount, "unsharpAmount", opts.UnsharpAmount, "unsharp amount")
+	flags.Float64Var(&opts.UnsharpThreshold, "unsharpThreshold", opts.UnsharpThreshold, "unsharp threshold")
+	flags.IntVar(&opts.MaxColors, "maxColors", opts.MaxColors, "max colors")
+	flags.StringVar(&opts.Palette, "palette", opts.Palette, "palette ("+strings.Join(lib.PaletteNames(), ", ")+")")
+	flags.Float64Var(&opts.ColorMergeTolerance, "colorMergeTolerance", opts.ColorMergeTolerance, "color merge tolerance (delta E, 0 to disable)")
+	flags.Float64Var(&opts.Rotate, "rotate", opts.Rotate, "rotate")
+	flags.StringVar(&opts.RotateFill, "rotateFill", opts.RotateFill, "rotate fill")
+	flags.BoolVar(&opts.FlipHorizontal, "flipHorizontal", opts.FlipHorizontal, "flip horizontal")
+	flags.BoolVar(&opts.FlipVertical, "flipVertical", opts.FlipVertical, "flip vertical")
+	flags.BoolVar(&opts.Deterministic, "deterministic", opts.Deterministic, "deterministic output")
+	flags.StringVar(&opts.OutputFormat, "outputFormat", opts.OutputFormat, "output format ("+strings.Join(lib.OutputFormats, ", ")+")")
+	flags.Float64Var(&opts.RasterScale, "rasterScale", opts.RasterScale, "raster scale")
+	flags.IntVar(&opts.FrameDelay, "frameDelay", opts.FrameDelay, "frame delay")
+	flags.StringVar(&opts.DefaultTextColor, "defaultTextColor", opts.DefaultTextColor, "default text color")
+	flags.StringVar(&opts.Border, "border", opts.Border, "border ("+strings.Join(lib.BorderNames(), ", ")+")")
+	flags.StringVar(&opts.BorderColor, "borderColor", opts.BorderColor, "border color")
+	flags.IntVar(&opts.BorderWidth, "borderWidth", opts.BorderWidth, "border width")
+	flags.IntVar(&opts.BorderPadding, "borderPadding", opts.BorderPadding, "border padding")
+	flags.BoolVar(&opts.TrimWhitespace, "trimWhitespace", opts.TrimWhitespace, "trim whitespace")
+	flags.Float64Var(&opts.DiffThreshold, "diffThreshold", opts.DiffThreshold, "diff threshold")
+	flags.StringVar(&opts.DiffColor, "diffColor", opts.DiffColor, "diff color")
+	flags.StringVar(&opts.Caption, "caption", opts.Caption, "caption")
+	flags.StringVar(&opts.CaptionPosition, "captionPosition", opts.CaptionPosition, "caption position ("+strings.Join(lib.CaptionPositionNames(), ", ")+")")
+	flags.StringVar(&opts.CaptionColor, "captionColor", opts.CaptionColor, "caption color")
+	flags.Float64Var(&opts.CaptionOpacity, "captionOpacity", opts.CaptionOpacity, "caption opacity")
+	flags.StringVar(&opts.OutlineColor, "outlineColor", opts.OutlineColor, "outline color")
+	flags.Float64Var(&opts.OutlineWidth, "outlineWidth", opts.OutlineWidth, "outline width")
+	flags.StringVar(&opts.ShadowColor, "shadowColor", opts.ShadowColor, "shadow color")
+	flags.Float64Var(&opts.ShadowOffset, "shadowOffset", opts.ShadowOffset, "shadow offset")
+	flags.Float64Var(&opts.ShadowBlur, "shadowBlur", opts.ShadowBlur, "shadow blur")
+	flags.StringVar(&opts.Title, "title", opts.Title, "title")
+	flags.StringVar(&opts.Description, "description", opts.Description, "description")
+	flags.StringVar(&opts.Author, "author", opts.Author, "author")
+	flags.BoolVar(&opts.Sauce, "sauce", opts.Sauce, "sauce record")
+	flags.StringVar(&opts.BackgroundGradient, "backgroundGradient", opts.BackgroundGradient, "background gradient")
+	flags.Float64Var(&opts.BackgroundGradientAngle, "backgroundGradientAngle", opts.BackgroundGradientAngle, "background gradient angle")
+	flags.StringVar(&opts.FontFamily, "fontFamily", opts.FontFamily, "font family")
+	flags.IntVar(&opts.CharWidth, "charWidth", opts.CharWidth, "char width")
+	flags.IntVar(&opts.LineHeight, "lineHeight", opts.LineHeight, "line height")
+	flags.IntVar(&opts.FontSize, "fontSize", opts.FontSize, "font size")
+	flags.Float64Var(&opts.LetterSpacing, "letterSpacing", opts.LetterSpacing, "letter spacing multiplier")
+	flags.Float64Var(&opts.LineSpacing, "lineSpacing", opts.LineSpacing, "line spacing multiplier")
+	flags.IntVar(&opts.PaddingTop, "paddingTop", opts.PaddingTop, "padding top")
+	flags.IntVar(&opts.PaddingBottom, "paddingBottom", opts.PaddingBottom, "padding bottom")
+	flags.IntVar(&opts.PaddingLeft, "paddingLeft", opts.PaddingLeft, "padding left")
+	flags.IntVar(&opts.PaddingRight, "paddingRight", opts.PaddingRight, "padding right")
+}
@@ -0,0 +1,125 @@
+//go:build !js
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"image-to-ascii-art/lib"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "img2ascii: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, stdout io.Writer) error {
+	opts := lib.DefaultOptions()
+	flags := flag.NewFlagSet("img2ascii", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: img2ascii [flags] <image>\n\nFlags:\n")
+		flags.PrintDefaults()
+	}
+
+	output := flags.String("o", "", "write output to `file` instead of stdout (format inferred from extension)")
+	logLevel := flags.String("logLevel", lib.LevelWarn.String(), "log `level`: silent, error, warn, info or debug")
+	embedFont := flags.String("embedFont", "", "font `file` to embed in SVG output")
+	diffWith := flags.String("diff", "", "highlight cells that differ from the image in `file`")
+	interactive := flags.Bool("interactive", false, "preview in the terminal and re-render when it is resized")
+	registerOptionFlags(flags, &opts)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return fmt.Errorf("expected exactly one image path, got %d", flags.NArg())
+	}
+
+	level, err := lib.ParseLogLevel(*logLevel)
+	if err != nil {
+		return err
+	}
+	lib.SetLogLevel(level)
+
+	if *embedFont != "" {
+		if opts.EmbedFont, err = os.ReadFile(*embedFont); err != nil {
+			return err
+		}
+	}
+	if *output != "" && !isFlagSet(flags, "outputFormat") {
+		if format := strings.TrimPrefix(filepath.Ext(*output), "."); slices.Contains(lib.OutputFormats, format) {
+			opts.OutputFormat = format
+		} else if format == "txt" {
+			opts.OutputFormat = lib.OutputText
+		}
+	}
+
+	imageData, err := os.ReadFile(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	var other []byte
+	if *diffWith != "" {
+		if other, err = os.ReadFile(*diffWith); err != nil {
+			return err
+		}
+	}
+	render := func(opts lib.Options) ([]byte, error) {
+		var result *lib.Result
+		if other != nil {
+			result, err = lib.DiffImages(imageData, other, opts)
+		} else {
+			result, err = lib.ProcessImage(imageData, opts)
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case result.Text != "":
+			return []byte(result.Text), nil
+		case result.Data == nil:
+			return []byte(result.SVG), nil
+		}
+		return result.Data, nil
+	}
+
+	terminal, _ := stdout.(*os.File)
+	if *interactive {
+		if terminal == nil || *output != "" {
+			return fmt.Errorf("interactive preview requires a terminal on stdout")
+		}
+		return preview(terminal, opts, isFlagSet(flags, "outputFormat"), render)
+	}
+
+	if terminal != nil && *output == "" && slices.Contains(terminalFormats, opts.OutputFormat) && !isFlagSet(flags, "targetWidth") {
+		if columns, rows, err := terminalSize(terminal); err == nil {
+			fitToTerminal(&opts, columns, rows, isFlagSet(flags, "fit"))
+		}
+	}
+	data, err := render(opts)
+	if err != nil {
+		return err
+	}
+	if *output == "" {
+		_, err = stdout.Write(data)
+		return err
+	}
+	return os.WriteFile(*output, data, 0o644)
+}
+
+func isFlagSet(flags *flag.FlagSet, name string) bool {
+	set := false
+	flags.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
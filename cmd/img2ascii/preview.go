@@ -0,0 +1,58 @@
+//go:build !js
+
+package main
+
+import (
+	"cmp"
+	"image-to-ascii-art/lib"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+var terminalFormats = []string{lib.OutputANSI, lib.OutputText}
+
+func fitToTerminal(opts *lib.Options, columns, rows int, fitHeight bool) {
+	if columns > 0 {
+		opts.TargetWidth = min(columns, cmp.Or(opts.MaxASCIIDimension, lib.CurrentLimits().MaxASCIIDimension))
+	}
+	if fitHeight && rows > 1 {
+		opts.TargetHeight = rows - 1
+	}
+}
+
+func preview(terminal *os.File, opts lib.Options, formatSet bool, render func(lib.Options) ([]byte, error)) error {
+	if !formatSet {
+		opts.OutputFormat = lib.OutputANSI
+	}
+	resized := make(chan os.Signal, 1)
+	notifyResize(resized)
+	interrupted := make(chan os.Signal, 1)
+	signal.Notify(interrupted, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(resized)
+	defer signal.Stop(interrupted)
+
+	for {
+		columns, rows, err := terminalSize(terminal)
+		if err != nil {
+			return err
+		}
+		fitToTerminal(&opts, columns, rows, true)
+		data, err := render(opts)
+		if err != nil {
+			return err
+		}
+		if _, err := terminal.WriteString("\x1b[H\x1b[2J"); err != nil {
+			return err
+		}
+		if _, err := terminal.Write(data); err != nil {
+			return err
+		}
+
+		select {
+		case <-resized:
+		case <-interrupted:
+			return nil
+		}
+	}
+}
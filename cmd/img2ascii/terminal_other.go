@@ -0,0 +1,14 @@
+//go:build !js && !unix
+
+package main
+
+import (
+	"errors"
+	"os"
+)
+
+func terminalSize(f *os.File) (int, int, error) {
+	return 0, 0, errors.New("terminal size detection is not supported on this platform")
+}
+
+func notifyResize(c chan<- os.Signal) {}
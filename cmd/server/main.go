@@ -0,0 +1,179 @@
+//go:build !js
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"image-to-ascii-art/lib"
+	"io"
+	"log"
+	"net/http"
+	"runtime"
+	"time"
+)
+
+const (
+	maxOptionsSize    = 64 * 1024
+	readHeaderTimeout = 10 * time.Second
+	readTimeout       = time.Minute
+	writeTimeout      = 2 * time.Minute
+)
+
+var contentTypes = map[string]string{
+	lib.OutputSVG:    "image/svg+xml; charset=utf-8",
+	lib.OutputSVGZ:   "image/svg+xml",
+	lib.OutputPDF:    "application/pdf",
+	lib.OutputPNG:    "image/png",
+	lib.OutputGIF:    "image/gif",
+	lib.OutputANSI:   "text/plain; charset=utf-8",
+	lib.OutputText:   "text/plain; charset=utf-8",
+	lib.OutputIRC:    "text/plain; charset=utf-8",
+	lib.OutputANS:    "text/plain; charset=ibm437",
+	lib.OutputITerm2: "text/plain; charset=utf-8",
+	lib.OutputKitty:  "text/plain; charset=utf-8",
+}
+
+var errorStatuses = map[lib.ErrorCode]int{
+	lib.CodeBadInput:  http.StatusBadRequest,
+	lib.CodeBadOption: http.StatusBadRequest,
+	lib.CodeDecode:    http.StatusUnprocessableEntity,
+	lib.CodeTooLarge:  http.StatusRequestEntityTooLarge,
+}
+
+type server struct {
+	slots chan struct{}
+}
+
+func main() {
+	addr := flag.String("addr", ":8080", "listen `address`")
+	maxConcurrent := flag.Int("maxConcurrent", runtime.NumCPU(), "maximum number of conversions running at once")
+	logLevel := flag.String("logLevel", lib.LevelWarn.String(), "log `level`: silent, error, warn, info or debug")
+	flag.Parse()
+
+	level, err := lib.ParseLogLevel(*logLevel)
+	if err != nil {
+		log.Fatal(err)
+	}
+	lib.SetLogLevel(level)
+	if *maxConcurrent < 1 {
+		log.Fatalf("maxConcurrent must be at least 1, got %d", *maxConcurrent)
+	}
+
+	s := &server{slots: make(chan struct{}, *maxConcurrent)}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /convert", s.handleConvert)
+
+	log.Printf("listening on %s", *addr)
+	srv := &http.Server{
+		Addr:              *addr,
+		Handler:           mux,
+		ReadHeaderTimeout: readHeaderTimeout,
+		ReadTimeout:       readTimeout,
+		WriteTimeout:      writeTimeout,
+	}
+	log.Fatal(srv.ListenAndServe())
+}
+
+func (s *server) handleConvert(w http.ResponseWriter, r *http.Request) {
+	imageData, opts, err := readConvertRequest(w, r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	select {
+	case s.slots <- struct{}{}:
+		defer func() { <-s.slots }()
+	case <-r.Context().Done():
+		return
+	}
+
+	result, err := lib.ProcessImage(imageData, opts)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	data := result.Data
+	switch {
+	case result.Text != "":
+		data = []byte(result.Text)
+	case data == nil:
+		data = []byte(result.SVG)
+	}
+	w.Header().Set("Content-Type", contentTypes[result.OutputFormat])
+	if result.OutputFormat == lib.OutputSVGZ {
+		w.Header().Set("Content-Encoding", "gzip")
+	}
+	w.Header().Set("X-ASCII-Width", fmt.Sprint(result.ASCIIWidth))
+	w.Header().Set("X-ASCII-Height", fmt.Sprint(result.ASCIIHeight))
+	for _, warning := range result.Warnings {
+		w.Header().Add("X-Warning", fmt.Sprintf("%s: %s", warning.Code, warning.Message))
+	}
+	w.Write(data)
+}
+
+func readConvertRequest(w http.ResponseWriter, r *http.Request) ([]byte, lib.Options, error) {
+	opts := lib.DefaultOptions()
+	maxImageSize := lib.CurrentLimits().MaxImageSize
+	r.Body = http.MaxBytesReader(w, r.Body, int64(maxImageSize)+maxOptionsSize)
+
+	reader, err := r.MultipartReader()
+	if err != nil {
+		return nil, opts, lib.NewError(lib.CodeBadInput, "expected a multipart/form-data request: %w", err)
+	}
+
+	var imageData []byte
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, opts, requestBodyError(err)
+		}
+
+		switch part.FormName() {
+		case "image":
+			imageData, err = io.ReadAll(io.LimitReader(part, int64(maxImageSize)+1))
+			if err != nil {
+				return nil, opts, requestBodyError(err)
+			}
+		case "options":
+			if err := lib.DecodeOptionsJSON(io.LimitReader(part, maxOptionsSize), &opts); err != nil {
+				return nil, opts, err
+			}
+		}
+		part.Close()
+	}
+
+	if imageData == nil {
+		return nil, opts, lib.NewError(lib.CodeBadInput, "missing \"image\" form field")
+	}
+	return imageData, opts, nil
+}
+
+func requestBodyError(err error) error {
+	var maxErr *http.MaxBytesError
+	if errors.As(err, &maxErr) {
+		return lib.NewError(lib.CodeTooLarge, "request body is too large (max: %d bytes)", maxErr.Limit)
+	}
+	return lib.NewError(lib.CodeBadInput, "failed to read request body: %w", err)
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	status, ok := errorStatuses[lib.CodeOf(err)]
+	if !ok {
+		status = http.StatusInternalServerError
+	}
+
+	var buf bytes.Buffer
+	json.NewEncoder(&buf).Encode(lib.ErrorFields(err))
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(buf.Bytes())
+}
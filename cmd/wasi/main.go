@@ -0,0 +1,89 @@
+//go:build wasip1
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"image-to-ascii-art/lib"
+	"unsafe"
+)
+
+var (
+	allocations = make(map[unsafe.Pointer][]byte)
+	lastResult  []byte
+)
+
+func main() {}
+
+//go:wasmexport alloc
+func alloc(size uint32) unsafe.Pointer {
+	if size == 0 {
+		return nil
+	}
+	buf := make([]byte, size)
+	ptr := unsafe.Pointer(&buf[0])
+	allocations[ptr] = buf
+	return ptr
+}
+
+//go:wasmexport free
+func free(ptr unsafe.Pointer) {
+	delete(allocations, ptr)
+}
+
+//go:wasmexport result_ptr
+func resultPtr() unsafe.Pointer {
+	if len(lastResult) == 0 {
+		return nil
+	}
+	return unsafe.Pointer(&lastResult[0])
+}
+
+//go:wasmexport result_len
+func resultLen() uint32 {
+	return uint32(len(lastResult))
+}
+
+//go:wasmexport set_log_level
+func setLogLevel(level int32) {
+	lib.SetLogLevel(lib.LogLevel(level))
+}
+
+//go:wasmexport process_image
+func processImage(imagePtr unsafe.Pointer, imageLen uint32, optionsPtr unsafe.Pointer, optionsLen uint32) int32 {
+	imageData := bytes.Clone(guestBytes(imagePtr, imageLen))
+	opts := lib.DefaultOptions()
+	if optionsLen > 0 {
+		if err := lib.DecodeOptionsJSON(bytes.NewReader(guestBytes(optionsPtr, optionsLen)), &opts); err != nil {
+			return setError(err)
+		}
+	}
+
+	result, err := lib.ProcessImage(imageData, opts)
+	if err != nil {
+		return setError(err)
+	}
+
+	switch {
+	case result.Data != nil:
+		lastResult = result.Data
+	case result.Text != "":
+		lastResult = []byte(result.Text)
+	default:
+		lastResult = []byte(result.SVG)
+	}
+	return 0
+}
+
+func guestBytes(ptr unsafe.Pointer, length uint32) []byte {
+	if ptr == nil || length == 0 {
+		return nil
+	}
+	return unsafe.Slice((*byte)(ptr), length)
+}
+
+func setError(err error) int32 {
+	lastResult, _ = json.Marshal(lib.ErrorFields(err))
+	return 1
+}
@@ -0,0 +1,69 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"fmt"
+	"image-to-ascii-art/lib"
+	"syscall/js"
+)
+
+func processCollageHandler(args []js.Value) (any, error) {
+	if len(args) < 1 || !js.Global().Get("Array").Call("isArray", args[0]).Bool() {
+		return nil, lib.NewError(lib.CodeBadInput, "expected an array of collage tiles as the first argument")
+	}
+
+	var layout lib.CollageLayout
+	if len(args) > 1 && args[1].Type() == js.TypeObject {
+		for name, target := range map[string]*int{"columns": &layout.Columns, "gap": &layout.Gap} {
+			value := args[1].Get(name)
+			if value.IsUndefined() {
+				continue
+			}
+			if value.Type() != js.TypeNumber {
+				return nil, lib.NewOptionError(name, "layout %q must be a number, got %s", name, value.Type())
+			}
+			*target = value.Int()
+		}
+	}
+
+	base := js.Undefined()
+	if len(args) > 2 {
+		base = args[2]
+	}
+	opts, err := parseOptionsObject(base)
+	if err != nil {
+		return nil, err
+	}
+
+	tiles := make([]lib.CollageTile, args[0].Length())
+	for i := range tiles {
+		tileJS := args[0].Index(i)
+		if tileJS.Type() != js.TypeObject {
+			return nil, lib.NewError(lib.CodeBadInput, "collage tile %d must be an object, got %s", i, tileJS.Type())
+		}
+		imageData, err := readImageData(tileJS.Get("image"))
+		if err != nil {
+			return nil, fmt.Errorf("collage tile %d: %w", i, err)
+		}
+
+		merged := js.Global().Get("Object").Call("assign", js.Global().Get("Object").New())
+		if base.Type() == js.TypeObject {
+			js.Global().Get("Object").Call("assign", merged, base)
+		}
+		if tileOptions := tileJS.Get("options"); tileOptions.Type() == js.TypeObject {
+			js.Global().Get("Object").Call("assign", merged, tileOptions)
+		}
+		tileOpts, err := parseOptionsObject(merged)
+		if err != nil {
+			return nil, fmt.Errorf("collage tile %d: %w", i, err)
+		}
+		tiles[i] = lib.CollageTile{ImageData: imageData, Options: tileOpts.Options}
+	}
+
+	result, err := lib.ProcessCollage(tiles, layout, opts.Options)
+	if err != nil {
+		return nil, fmt.Errorf("error building collage: %w", err)
+	}
+	return resultToJS(result, opts.detailed), nil
+}
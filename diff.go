@@ -0,0 +1,35 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"fmt"
+	"image-to-ascii-art/lib"
+	"syscall/js"
+)
+
+func diffImagesHandler(args []js.Value) (any, error) {
+	if len(args) < 2 {
+		return nil, lib.NewError(lib.CodeBadInput, "expected two images to compare")
+	}
+
+	imageA, err := readImageData(args[0])
+	if err != nil {
+		return nil, err
+	}
+	imageB, err := readImageData(args[1])
+	if err != nil {
+		return nil, err
+	}
+	opts, err := parseOptions(args[2:])
+	if err != nil {
+		return nil, err
+	}
+	logOptions(opts.Options)
+
+	result, err := lib.DiffImages(imageA, imageB, opts.Options)
+	if err != nil {
+		return nil, fmt.Errorf("error comparing images: %w", err)
+	}
+	return resultToJS(result, opts.detailed), nil
+}
@@ -0,0 +1,87 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"fmt"
+	"image-to-ascii-art/lib"
+	"syscall/js"
+)
+
+func processFramesHandler(args []js.Value) (any, error) {
+	frames, width, height, opts, err := validateFramesParams(args)
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := lib.ProcessFrames(frames, width, height, opts.Options)
+	if err != nil {
+		return nil, fmt.Errorf("error processing frames: %w", err)
+	}
+
+	output := make([]any, len(results))
+	for i, result := range results {
+		output[i] = resultToJS(result, opts.detailed)
+	}
+	return js.ValueOf(output), nil
+}
+
+func processAnimationHandler(args []js.Value) (any, error) {
+	frames, width, height, opts, err := validateFramesParams(args)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := lib.ProcessAnimation(frames, width, height, opts.Options)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding animation: %w", err)
+	}
+	return resultToJS(result, opts.detailed), nil
+}
+
+func validateFramesParams(args []js.Value) ([][]byte, int, int, requestOptions, error) {
+	if len(args) < 3 {
+		return nil, 0, 0, requestOptions{}, lib.NewError(lib.CodeBadInput, "expected frames, width and height")
+	}
+	if !js.Global().Get("Array").Call("isArray", args[0]).Bool() {
+		return nil, 0, 0, requestOptions{}, lib.NewError(lib.CodeBadInput, "frames must be an array, got %s", args[0].Type())
+	}
+	if args[1].Type() != js.TypeNumber || args[2].Type() != js.TypeNumber {
+		return nil, 0, 0, requestOptions{}, lib.NewError(lib.CodeBadInput, "frame width and height must be numbers")
+	}
+	opts, err := parseOptions(args[3:])
+	if err != nil {
+		return nil, 0, 0, requestOptions{}, err
+	}
+	logOptions(opts.Options)
+
+	width, height := args[1].Int(), args[2].Int()
+	if err := lib.ValidateFrames(args[0].Length(), width, height); err != nil {
+		return nil, 0, 0, requestOptions{}, err
+	}
+	sources := make([]js.Value, args[0].Length())
+	for i := range sources {
+		frame := args[0].Index(i)
+		if frame.Type() == js.TypeObject && !isByteArray(frame) {
+			frame = frame.Get("data")
+		}
+		if frame.Type() != js.TypeObject || !isByteArray(frame) {
+			return nil, 0, 0, requestOptions{}, lib.NewError(lib.CodeBadInput, "frame %d must be a Uint8ClampedArray, Uint8Array or ImageData", i)
+		}
+		if err := lib.ValidateFrameLength(i, frame.Length(), width, height); err != nil {
+			return nil, 0, 0, requestOptions{}, err
+		}
+		sources[i] = frame
+	}
+
+	frames := make([][]byte, len(sources))
+	for i, frame := range sources {
+		frames[i] = make([]byte, frame.Length())
+		js.CopyBytesToGo(frames[i], frame)
+	}
+	return frames, width, height, opts, nil
+}
+
+func isByteArray(v js.Value) bool {
+	return v.InstanceOf(js.Global().Get("Uint8ClampedArray")) || v.InstanceOf(js.Global().Get("Uint8Array"))
+}
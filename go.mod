@@ -6,16 +6,8 @@ require (
 	github.com/ajstarks/svgo v0.0.0-20211024235047-1546f124cd8b
 	github.com/disintegration/imaging v1.6.2
 	github.com/leaanthony/go-ansi-parser v1.6.1
-	github.com/qeesung/image2ascii v1.0.1
+	golang.org/x/image v0.0.0-20191009234506-e7c1f5e7dbb8
+	golang.org/x/sys v0.6.0
 )
 
-require (
-	github.com/aybabtme/rgbterm v0.0.0-20170906152045-cc83f3b3ce59 // indirect
-	github.com/mattn/go-isatty v0.0.20 // indirect
-	github.com/nfnt/resize v0.0.0-20180221191011-83c6a9932646 // indirect
-	github.com/rivo/uniseg v0.2.0 // indirect
-	github.com/stretchr/testify v1.11.1 // indirect
-	github.com/wayneashleyberry/terminal-dimensions v1.1.0 // indirect
-	golang.org/x/image v0.0.0-20191009234506-e7c1f5e7dbb8 // indirect
-	golang.org/x/sys v0.6.0 // indirect
-)
+require github.com/rivo/uniseg v0.2.0 // indirect
@@ -0,0 +1,57 @@
+package lib
+
+import (
+	"image"
+	"time"
+)
+
+func ProcessAnimation(frames [][]byte, width, height int, opts Options) (*Result, error) {
+	start := time.Now()
+	opts.OutputFormat = OutputGIF
+	opts, err := prepareFrames(frames, width, height, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	frameOpts := opts
+	frameOpts.Progress = nil
+	limits := opts.limits()
+	result := &Result{Format: "rgba", OutputFormat: OutputGIF}
+	paletted := make([]*image.Paletted, 0, len(frames))
+	palettedBytes := 0
+	err = eachFrame(frames, width, height, opts, func(img image.Image) error {
+		processedImg := adjustImage(img, frameOpts)
+		defer releaseAdjusted(processedImg)
+		styledText, asciiWidth, asciiHeight, err := buildStyledText(processedImg, frameOpts)
+		if err != nil {
+			return err
+		}
+		raster, err := rasterize(styledText, frameOpts)
+		if err != nil {
+			return err
+		}
+		frame := palettize(raster)
+		if palettedBytes += len(frame.Pix); palettedBytes > limits.MaxMemory {
+			return newLimitError(palettedBytes, limits.MaxMemory, "animation frames exceed the memory budget of %s bytes", formatNumber(limits.MaxMemory))
+		}
+		paletted = append(paletted, frame)
+		result.ASCIIWidth, result.ASCIIHeight = asciiWidth, asciiHeight
+		result.CharCount += asciiWidth * asciiHeight
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := encodeGIF(paletted, opts.FrameDelay)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > limits.MaxOutputSize {
+		return nil, newLimitError(len(data), limits.MaxOutputSize, "output GIF is too large: %d bytes (max: %d)", len(data), limits.MaxOutputSize)
+	}
+	result.Data = data
+	result.Warnings = opts.collectedWarnings()
+	result.Elapsed = opts.elapsedSince(start)
+	return result, nil
+}
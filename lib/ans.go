@@ -0,0 +1,135 @@
+package lib
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/leaanthony/go-ansi-parser"
+)
+
+const (
+	sauceTitleLength  = 35
+	sauceAuthorLength = 20
+	sauceGroupLength  = 20
+	sauceFontLength   = 22
+	sauceFont         = "IBM VGA"
+	sauceICEColors    = 0x01
+)
+
+const cp437High = "ÇüéâäàåçêëèïîìÄÅÉæÆôöòûùÿÖÜ¢£¥₧ƒáíóúñÑªº¿⌐¬½¼¡«»" +
+	"░▒▓│┤╡╢╖╕╣║╗╝╜╛┐└┴┬├─┼╞╟╚╔╩╦╠═╬╧╨╤╥╙╘╒╓╫╪┘┌█▄▌▐▀" +
+	"αßΓπΣσµτΦΘΩδ∞φε∩≡±≥≤⌠⌡÷≈°∙·√ⁿ²■ "
+
+var cp437 = func() map[rune]byte {
+	table := make(map[rune]byte, 128)
+	b := 0x80
+	for _, r := range cp437High {
+		table[r] = byte(b)
+		b++
+	}
+	return table
+}()
+
+func cp437Byte(char rune) byte {
+	if char < 0x80 {
+		return byte(char)
+	}
+	if b, ok := cp437[char]; ok {
+		return b
+	}
+	return '?'
+}
+
+func renderToANS(styledText []*ansi.StyledText, opts Options) ([]byte, error) {
+	if styledText == nil {
+		return nil, NewError(CodeRender, "styledText is nil")
+	}
+
+	var buf bytes.Buffer
+	palette := terminalPalette(PaletteANSI16)
+	lines := splitStyledTextByLine(styledText)
+	width, iceColors := 0, false
+	for _, line := range lines {
+		sgr, columns := "", 0
+		for _, styledChar := range line {
+			fg, bg := 7, 0
+			if hex := colorHex(styledChar.FgCol); hex != "" {
+				fg = nearestPaletteIndex(palette, hexToRGB(hex))
+			}
+			if hex := colorHex(styledChar.BgCol); hex != "" {
+				bg = nearestPaletteIndex(palette, hexToRGB(hex))
+			}
+			iceColors = iceColors || bg >= 8
+			if next := ansSGR(fg, bg); next != sgr {
+				sgr = next
+				buf.WriteString(sgr)
+			}
+			for _, char := range styledChar.Label {
+				buf.WriteByte(cp437Byte(char))
+				columns++
+			}
+		}
+		width = max(width, columns)
+		buf.WriteString("\x1b[0m\r\n")
+	}
+	height := len(lines)
+	if opts.Caption != "" {
+		for _, char := range opts.Caption {
+			buf.WriteByte(cp437Byte(char))
+		}
+		buf.WriteString("\r\n")
+		width, height = max(width, utf8.RuneCountInString(opts.Caption)), height+1
+	}
+
+	if opts.Sauce {
+		writeSauce(&buf, opts, width, height, iceColors)
+	}
+	return buf.Bytes(), nil
+}
+
+func ansSGR(fg, bg int) string {
+	sgr := "\x1b[0"
+	if fg >= 8 {
+		sgr += ";1"
+	}
+	if bg >= 8 {
+		sgr += ";5"
+	}
+	return sgr + fmt.Sprintf(";%d;%dm", 30+fg%8, 40+bg%8)
+}
+
+func writeSauce(buf *bytes.Buffer, opts Options, width, height int, iceColors bool) {
+	fileSize := buf.Len()
+	buf.WriteByte(0x1A)
+	buf.WriteString("SAUCE00")
+	writeSauceField(buf, opts.Title, sauceTitleLength)
+	writeSauceField(buf, opts.Author, sauceAuthorLength)
+	writeSauceField(buf, "", sauceGroupLength)
+	buf.WriteString(opts.now().UTC().Format("20060102"))
+	binary.Write(buf, binary.LittleEndian, uint32(fileSize))
+	buf.WriteByte(1)
+	buf.WriteByte(1)
+	binary.Write(buf, binary.LittleEndian, [4]uint16{uint16(width), uint16(height), 0, 0})
+	buf.WriteByte(0)
+	var flags byte
+	if iceColors {
+		flags |= sauceICEColors
+	}
+	buf.WriteByte(flags)
+	buf.WriteString(sauceFont)
+	buf.Write(make([]byte, sauceFontLength-len(sauceFont)))
+}
+
+func writeSauceField(buf *bytes.Buffer, value string, length int) {
+	n := 0
+	for _, char := range value {
+		if n == length {
+			break
+		}
+		buf.WriteByte(cp437Byte(char))
+		n++
+	}
+	buf.Write(bytes.Repeat([]byte{' '}, length-n))
+}
@@ -0,0 +1,30 @@
+package lib
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/ajstarks/svgo"
+)
+
+const backgroundGradientID = "bg"
+
+func writeBackground(canvas *svg.SVG, width, height int, opts Options) {
+	if opts.BackgroundGradient == "" {
+		canvas.Rect(0, 0, width, height, fmt.Sprintf("fill:%s", opts.BackgroundColor))
+		return
+	}
+
+	angle := opts.BackgroundGradientAngle * math.Pi / 180
+	dx, dy := math.Cos(angle)*50, math.Sin(angle)*50
+	canvas.Def()
+	canvas.LinearGradient(backgroundGradientID,
+		uint8(math.Round(50-dx)), uint8(math.Round(50-dy)),
+		uint8(math.Round(50+dx)), uint8(math.Round(50+dy)),
+		[]svg.Offcolor{
+			{Offset: 0, Color: opts.BackgroundColor, Opacity: 1},
+			{Offset: 100, Color: opts.BackgroundGradient, Opacity: 1},
+		})
+	canvas.DefEnd()
+	canvas.Rect(0, 0, width, height, fmt.Sprintf("fill:url(#%s)", backgroundGradientID))
+}
@@ -0,0 +1,92 @@
+package lib
+
+import (
+	"cmp"
+	"strings"
+
+	"github.com/leaanthony/go-ansi-parser"
+)
+
+const (
+	BorderNone  = "none"
+	BorderRect  = "rect"
+	BorderASCII = "ascii"
+
+	maxBorderWidth   = 64
+	maxBorderPadding = 256
+)
+
+var (
+	lightBoxChars = [6]string{"┌", "┐", "└", "┘", "─", "│"}
+	heavyBoxChars = [6]string{"┏", "┓", "┗", "┛", "━", "┃"}
+)
+
+func BorderNames() []string {
+	return []string{BorderNone, BorderRect, BorderASCII}
+}
+
+func borderColor(opts Options) string {
+	return cmp.Or(opts.BorderColor, fallbackTextColor(opts))
+}
+
+func borderInset(opts Options) int {
+	if opts.Border != BorderRect {
+		return 0
+	}
+	return opts.BorderWidth + opts.BorderPadding
+}
+
+func borderRects(opts Options, width, height int) [][4]float64 {
+	if opts.Border != BorderRect {
+		return nil
+	}
+	w, h, t := float64(width), float64(height), float64(opts.BorderWidth)
+	return [][4]float64{
+		{0, 0, w, t},
+		{0, h - t, w, t},
+		{0, t, t, h - 2*t},
+		{w - t, t, t, h - 2*t},
+	}
+}
+
+func addTextBorder(styledText []*ansi.StyledText, opts Options) []*ansi.StyledText {
+	if opts.Border != BorderASCII {
+		return styledText
+	}
+
+	box := lightBoxChars
+	if opts.BorderWidth > 1 {
+		box = heavyBoxChars
+	}
+	rgb := hexToRGB(borderColor(opts))
+	col := &ansi.Col{Hex: borderColor(opts), Rgb: ansi.Rgb{R: rgb[0], G: rgb[1], B: rgb[2]}}
+
+	lines := splitStyledTextByLine(styledText)
+	innerWidth := 0
+	for _, line := range lines {
+		innerWidth = max(innerWidth, lineWidth(line))
+	}
+	pad := opts.BorderPadding
+	spanWidth := innerWidth + 2*pad
+
+	result := make([]*ansi.StyledText, 0, len(styledText)+len(lines)*4+4)
+	frame := func(label string) {
+		result = append(result, &ansi.StyledText{Label: label, FgCol: col})
+	}
+	blankRow := box[5] + strings.Repeat(" ", spanWidth) + box[5] + "\n"
+
+	frame(box[0] + strings.Repeat(box[4], spanWidth) + box[1] + "\n")
+	for i := 0; i < pad; i++ {
+		frame(blankRow)
+	}
+	for _, line := range lines {
+		frame(box[5] + strings.Repeat(" ", pad))
+		result = append(result, line...)
+		frame(strings.Repeat(" ", pad+innerWidth-lineWidth(line)) + box[5] + "\n")
+	}
+	for i := 0; i < pad; i++ {
+		frame(blankRow)
+	}
+	frame(box[2] + strings.Repeat(box[4], spanWidth) + box[3])
+	return result
+}
@@ -0,0 +1,73 @@
+package lib
+
+import (
+	"image"
+
+	"github.com/disintegration/imaging"
+)
+
+const (
+	brailleBase      = 0x2800
+	brailleThreshold = 0.5
+)
+
+var brailleDots = [4][2]rune{
+	{0x01, 0x08},
+	{0x02, 0x10},
+	{0x04, 0x20},
+	{0x40, 0x80},
+}
+
+func renderBraille(img image.Image, width, height int, opts Options) Grid {
+	resized := imaging.Resize(img, width*2, height*4, resampleFilter(opts.Resample))
+	dotsWide, dotsHigh := width*2, height*4
+
+	values := make([]float64, dotsWide*dotsHigh)
+	for y := 0; y < dotsHigh; y++ {
+		for x := 0; x < dotsWide; x++ {
+			i := y*resized.Stride + x*4
+			values[y*dotsWide+x] = intensityOf(resized.Pix[i:i+4], opts.LuminanceFormula)
+		}
+	}
+	if opts.Invert {
+		invertValues(values)
+	}
+	applyDither(values, dotsWide, dotsHigh, 2, opts.Dither)
+
+	mono := hexToRGB(opts.MonochromeColor)
+	grid := newGrid(width, height)
+	for row := 0; row < height; row++ {
+		for col := 0; col < width; col++ {
+			var pattern rune
+			var sumR, sumG, sumB, count int
+			for dy := 0; dy < 4; dy++ {
+				for dx := 0; dx < 2; dx++ {
+					x, y := col*2+dx, row*4+dy
+					if values[y*dotsWide+x] < brailleThreshold {
+						continue
+					}
+					i := y*resized.Stride + x*4
+					r, g, b := resized.Pix[i], resized.Pix[i+1], resized.Pix[i+2]
+					pattern |= brailleDots[dy][dx]
+					sumR += int(r)
+					sumG += int(g)
+					sumB += int(b)
+					count++
+				}
+			}
+
+			cell := &grid[row][col]
+			if pattern == 0 {
+				cell.Char = ' '
+				continue
+			}
+			cell.Char = brailleBase + pattern
+			if opts.Grayscale {
+				cell.FG = rgbColor(mono[0], mono[1], mono[2])
+				continue
+			}
+			cell.FG = rgbColor(uint8(sumR/count), uint8(sumG/count), uint8(sumB/count))
+		}
+	}
+	return grid
+}
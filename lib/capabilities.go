@@ -0,0 +1,20 @@
+package lib
+
+const (
+	OutputSVG    = "svg"
+	OutputSVGZ   = "svgz"
+	OutputPDF    = "pdf"
+	OutputPNG    = "png"
+	OutputGIF    = "gif"
+	OutputANSI   = "ansi"
+	OutputText   = "text"
+	OutputIRC    = "irc"
+	OutputANS    = "ans"
+	OutputITerm2 = "iterm2"
+	OutputKitty  = "kitty"
+)
+
+var (
+	InputFormats  = []string{"png", "jpeg"}
+	OutputFormats = []string{OutputSVG, OutputSVGZ, OutputPDF, OutputPNG, OutputGIF, OutputANSI, OutputText, OutputIRC, OutputANS, OutputITerm2, OutputKitty}
+)
@@ -0,0 +1,57 @@
+package lib
+
+import "cmp"
+
+const (
+	CaptionBelow       = "below"
+	CaptionTopLeft     = "top-left"
+	CaptionTopRight    = "top-right"
+	CaptionBottomLeft  = "bottom-left"
+	CaptionBottomRight = "bottom-right"
+
+	maxCaptionLength = 256
+)
+
+func CaptionPositionNames() []string {
+	return []string{CaptionBelow, CaptionTopLeft, CaptionTopRight, CaptionBottomLeft, CaptionBottomRight}
+}
+
+func captionColor(opts Options) string {
+	return cmp.Or(opts.CaptionColor, fallbackTextColor(opts))
+}
+
+func captionExtraHeight(opts Options, m cellMetrics) int {
+	if opts.Caption == "" || opts.CaptionPosition != CaptionBelow {
+		return 0
+	}
+	return m.lineHeight + captionBorderGap(opts, m)
+}
+
+func captionBorderGap(opts Options, m cellMetrics) int {
+	if opts.Border != BorderRect {
+		return 0
+	}
+	return opts.BorderWidth + max(-m.paddingTop, 0)
+}
+
+func captionOrigin(opts Options, m cellMetrics, width, height int) (left, top int) {
+	margin := m.charWidth / 2
+	if opts.Border == BorderRect {
+		margin += opts.BorderWidth
+	}
+	textWidth := stringWidth(opts.Caption) * m.charWidth
+	left, top = margin, margin
+	switch opts.CaptionPosition {
+	case CaptionTopRight:
+		left = width - margin - textWidth
+	case CaptionBottomLeft:
+		top = height - margin - m.lineHeight
+	case CaptionBottomRight:
+		left = width - margin - textWidth
+		top = height - margin - m.lineHeight
+	case CaptionBelow:
+		left = width - m.charWidth/2 - textWidth
+		top = height - m.lineHeight
+	}
+	return max(left, 0), top
+}
@@ -0,0 +1,64 @@
+package lib
+
+import (
+	"image"
+	"unicode"
+
+	"github.com/disintegration/imaging"
+)
+
+const charSelectBatchSize = 4096
+
+type CharSample struct {
+	Luminance float64
+	R, G, B   uint8
+}
+
+type CharSelectFunc func(samples []CharSample) ([]rune, error)
+
+func selectChars(grid Grid, img image.Image, opts Options) error {
+	width, height := len(grid[0]), len(grid)
+	resized := imaging.Resize(img, width, height, resampleFilter(opts.Resample))
+
+	samples := make([]CharSample, 0, charSelectBatchSize)
+	cells := make([]*Cell, 0, charSelectBatchSize)
+	flush := func() error {
+		chars, err := opts.CharSelector(samples)
+		if err != nil {
+			return NewError(CodeConvert, "character selector failed: %w", err)
+		}
+		if len(chars) != len(samples) {
+			return NewError(CodeConvert, "character selector returned %d characters for %d cells", len(chars), len(samples))
+		}
+		for i, char := range chars {
+			if !unicode.IsPrint(char) {
+				char = ' '
+			}
+			cells[i].Char = char
+		}
+		samples, cells = samples[:0], cells[:0]
+		return nil
+	}
+
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			pix := resized.Pix[y*resized.Stride+x*4:]
+			samples = append(samples, CharSample{
+				Luminance: intensityOf(pix[:4], opts.LuminanceFormula),
+				R:         pix[0],
+				G:         pix[1],
+				B:         pix[2],
+			})
+			cells = append(cells, &grid[y][x])
+			if len(samples) == charSelectBatchSize {
+				if err := flush(); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	if len(samples) > 0 {
+		return flush()
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package lib
+
+import (
+	"image"
+	"math"
+	"sort"
+
+	"github.com/disintegration/imaging"
+)
+
+const defaultCharset = "standard"
+
+var charsetPresets = map[string]string{
+	"standard": " .,:;i1tfLCG08@",
+	"blocks":   " ░▒▓█",
+	"cp437":    " ·∙░▒▓█",
+	"minimal":  " .:oO@",
+	"dots":     " .·•●",
+	"binary":   "01",
+}
+
+func CharsetNames() []string {
+	names := make([]string, 0, len(charsetPresets))
+	for name := range charsetPresets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func renderCharset(img image.Image, width, height int, opts Options) Grid {
+	resized := imaging.Resize(img, width, height, resampleFilter(opts.Resample))
+	ramp := []rune(charsetPresets[opts.Charset])
+
+	values := make([]float64, width*height)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			i := y*resized.Stride + x*4
+			values[y*width+x] = intensityOf(resized.Pix[i:i+4], opts.LuminanceFormula)
+		}
+	}
+	if opts.Invert {
+		invertValues(values)
+	}
+	applyDither(values, width, height, len(ramp), opts.Dither)
+
+	mono := hexToRGB(opts.MonochromeColor)
+	grid := newGrid(width, height)
+	scale := float64(len(ramp) - 1)
+	for y := 0; y < height; y++ {
+		row := resized.Pix[y*resized.Stride : y*resized.Stride+width*4]
+		for x := 0; x < width; x++ {
+			value := math.Max(0, math.Min(1, values[y*width+x]))
+			cell := &grid[y][x]
+			cell.Char = ramp[int(math.Floor(value*scale+0.5))]
+			if opts.Grayscale {
+				cell.FG = rgbColor(mono[0], mono[1], mono[2])
+				continue
+			}
+			cell.FG = rgbColor(row[x*4], row[x*4+1], row[x*4+2])
+		}
+	}
+	return grid
+}
+
+func invertValues(values []float64) {
+	for i, v := range values {
+		values[i] = 1 - v
+	}
+}
@@ -0,0 +1,108 @@
+package lib
+
+import (
+	"image"
+	"math"
+
+	"github.com/disintegration/imaging"
+)
+
+const defaultClaheTiles = 8
+
+func applyCLAHE(img image.Image, clipLimit float64, tiles int) image.Image {
+	src := imaging.Clone(img)
+	bounds := src.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	tilesX, tilesY := min(tiles, width), min(tiles, height)
+
+	lum := make([]uint8, width*height)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			i := y*src.Stride + x*4
+			lum[y*width+x] = clampUint8(intensityOf([]uint8{src.Pix[i], src.Pix[i+1], src.Pix[i+2], 255}, LuminanceLuminosity) * 255)
+		}
+	}
+
+	luts := make([][256]uint8, tilesX*tilesY)
+	for ty := 0; ty < tilesY; ty++ {
+		for tx := 0; tx < tilesX; tx++ {
+			x0, x1 := tx*width/tilesX, (tx+1)*width/tilesX
+			y0, y1 := ty*height/tilesY, (ty+1)*height/tilesY
+			luts[ty*tilesX+tx] = claheTileLUT(lum, width, x0, y0, x1, y1, clipLimit)
+		}
+	}
+
+	tileW := float64(width) / float64(tilesX)
+	tileH := float64(height) / float64(tilesY)
+	for y := 0; y < height; y++ {
+		gy := float64(y)/tileH - 0.5
+		ty0 := clampInt(int(math.Floor(gy)), 0, tilesY-1)
+		ty1 := clampInt(ty0+1, 0, tilesY-1)
+		wy := clampFloat(gy-float64(ty0), 0, 1)
+		for x := 0; x < width; x++ {
+			gx := float64(x)/tileW - 0.5
+			tx0 := clampInt(int(math.Floor(gx)), 0, tilesX-1)
+			tx1 := clampInt(tx0+1, 0, tilesX-1)
+			wx := clampFloat(gx-float64(tx0), 0, 1)
+
+			l := lum[y*width+x]
+			top := float64(luts[ty0*tilesX+tx0][l])*(1-wx) + float64(luts[ty0*tilesX+tx1][l])*wx
+			bottom := float64(luts[ty1*tilesX+tx0][l])*(1-wx) + float64(luts[ty1*tilesX+tx1][l])*wx
+			mapped := top*(1-wy) + bottom*wy
+
+			i := y*src.Stride + x*4
+			if l == 0 {
+				v := clampUint8(mapped)
+				src.Pix[i], src.Pix[i+1], src.Pix[i+2] = v, v, v
+				continue
+			}
+			ratio := mapped / float64(l)
+			src.Pix[i] = clampUint8(float64(src.Pix[i]) * ratio)
+			src.Pix[i+1] = clampUint8(float64(src.Pix[i+1]) * ratio)
+			src.Pix[i+2] = clampUint8(float64(src.Pix[i+2]) * ratio)
+		}
+	}
+	return src
+}
+
+func claheTileLUT(lum []uint8, stride, x0, y0, x1, y1 int, clipLimit float64) [256]uint8 {
+	var histogram [256]int
+	for y := y0; y < y1; y++ {
+		for x := x0; x < x1; x++ {
+			histogram[lum[y*stride+x]]++
+		}
+	}
+
+	pixels := (x1 - x0) * (y1 - y0)
+	limit := max(1, int(clipLimit*float64(pixels)/256))
+	excess := 0
+	for i, count := range histogram {
+		if count > limit {
+			excess += count - limit
+			histogram[i] = limit
+		}
+	}
+	bonus, remainder := excess/256, excess%256
+	for i := range histogram {
+		histogram[i] += bonus
+		if i < remainder {
+			histogram[i]++
+		}
+	}
+
+	var lut [256]uint8
+	sum := 0
+	for i, count := range histogram {
+		sum += count
+		lut[i] = clampUint8(float64(sum) * 255 / float64(max(pixels, 1)))
+	}
+	return lut
+}
+
+func clampInt(v, lo, hi int) int {
+	return max(lo, min(hi, v))
+}
+
+func clampFloat(v, lo, hi float64) float64 {
+	return max(lo, min(hi, v))
+}
@@ -0,0 +1,150 @@
+package lib
+
+import (
+	"strings"
+	"time"
+
+	"github.com/leaanthony/go-ansi-parser"
+)
+
+const (
+	MaxCollageTiles   = 16
+	maxCollageGap     = 20
+	collageFormatName = "collage"
+)
+
+type CollageTile struct {
+	ImageData []byte
+	Options   Options
+}
+
+type CollageLayout struct {
+	Columns int
+	Gap     int
+}
+
+type collageCell struct {
+	lines  [][]*ansi.StyledText
+	width  int
+	height int
+}
+
+func ProcessCollage(tiles []CollageTile, layout CollageLayout, opts Options) (*Result, error) {
+	start := time.Now()
+	if len(tiles) == 0 || len(tiles) > MaxCollageTiles {
+		return nil, NewError(CodeBadInput, "expected between 1 and %d collage tiles, got %d", MaxCollageTiles, len(tiles))
+	}
+	if layout.Columns < 0 {
+		return nil, NewOptionError("columns", "collage columns must not be negative, got %d", layout.Columns)
+	}
+	if layout.Gap < 0 || layout.Gap > maxCollageGap {
+		return nil, NewOptionError("gap", "collage gap must be between 0 and %d, got %d", maxCollageGap, layout.Gap)
+	}
+	if err := validateOptions(opts); err != nil {
+		return nil, err
+	}
+	opts.setDefaults()
+	columns := layout.Columns
+	if columns == 0 {
+		columns = len(tiles)
+	}
+
+	cells := make([]collageCell, len(tiles))
+	for i, tile := range tiles {
+		cell, err := renderCollageTile(tile, opts.warnings)
+		if err != nil {
+			return nil, err
+		}
+		cells[i] = cell
+		opts.reportProgress(StageASCII, 75*float64(i+1)/float64(len(tiles)))
+	}
+
+	styledText, width, height := composeCollage(cells, columns, layout.Gap)
+	if limit := opts.limits().MaxASCIIChars; width*height > limit {
+		return nil, newLimitError(width*height, limit, "collage is too large: %s characters (max: %s)", formatNumber(width*height), formatNumber(limit))
+	}
+	return renderStyledText(addTextBorder(styledText, opts), width, height, collageFormatName, start, opts)
+}
+
+func renderCollageTile(tile CollageTile, warnings *warningList) (collageCell, error) {
+	opts := tile.Options
+	opts.warnings = warnings
+	if err := validateInput(tile.ImageData, opts); err != nil {
+		return collageCell{}, err
+	}
+	opts.setDefaults()
+	opts.Progress = nil
+	if err := checkImageMemory(tile.ImageData, opts); err != nil {
+		return collageCell{}, err
+	}
+
+	img, _, err := decodeImage(tile.ImageData)
+	if err != nil {
+		return collageCell{}, err
+	}
+	processedImg := adjustImage(opts.downscale(img), opts)
+	defer releaseAdjusted(processedImg)
+
+	styledText, _, _, err := buildStyledText(processedImg, opts)
+	if err != nil {
+		return collageCell{}, err
+	}
+	lines := splitStyledTextByLine(styledText)
+	cell := collageCell{lines: lines, height: len(lines)}
+	for _, line := range lines {
+		cell.width = max(cell.width, lineWidth(line))
+	}
+	return cell, nil
+}
+
+func composeCollage(cells []collageCell, columns, gap int) ([]*ansi.StyledText, int, int) {
+	columns = min(columns, len(cells))
+	columnWidths := make([]int, columns)
+	var rowHeights []int
+	for i, cell := range cells {
+		columnWidths[i%columns] = max(columnWidths[i%columns], cell.width)
+		if i%columns == 0 {
+			rowHeights = append(rowHeights, 0)
+		}
+		rowHeights[len(rowHeights)-1] = max(rowHeights[len(rowHeights)-1], cell.height)
+	}
+
+	width := gap * (columns - 1)
+	for _, w := range columnWidths {
+		width += w
+	}
+	height := gap * (len(rowHeights) - 1)
+	for _, h := range rowHeights {
+		height += h
+	}
+
+	var styledText []*ansi.StyledText
+	blank := func(n int) {
+		if n > 0 {
+			styledText = append(styledText, &ansi.StyledText{Label: strings.Repeat(" ", n)})
+		}
+	}
+	for row, rowHeight := range rowHeights {
+		if row > 0 {
+			for i := 0; i < gap; i++ {
+				blank(width)
+				styledText = append(styledText, &ansi.StyledText{Label: "\n"})
+			}
+		}
+		for y := 0; y < rowHeight; y++ {
+			for col := 0; col < columns; col++ {
+				if col > 0 {
+					blank(gap)
+				}
+				used := 0
+				if i := row*columns + col; i < len(cells) && y < len(cells[i].lines) {
+					styledText = append(styledText, cells[i].lines[y]...)
+					used = lineWidth(cells[i].lines[y])
+				}
+				blank(columnWidths[col] - used)
+			}
+			styledText = append(styledText, &ansi.StyledText{Label: "\n"})
+		}
+	}
+	return styledText, width, height
+}
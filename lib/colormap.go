@@ -0,0 +1,65 @@
+package lib
+
+import (
+	"fmt"
+	"image"
+
+	"github.com/leaanthony/go-ansi-parser"
+)
+
+type colorMapper func(r, g, b uint8) (uint8, uint8, uint8)
+
+func outputColorMappers(img image.Image, opts Options) []colorMapper {
+	var mappers []colorMapper
+	if opts.MaxColors > 0 {
+		mappers = append(mappers, paletteMapper(medianCutPalette(img, opts.MaxColors)))
+	}
+	if opts.DuotoneShadow != "" && opts.DuotoneHighlight != "" {
+		mappers = append(mappers, duotoneMapper(hexToRGB(opts.DuotoneShadow), hexToRGB(opts.DuotoneHighlight)))
+	}
+	if opts.Palette != PaletteTrueColor {
+		mappers = append(mappers, paletteMapper(terminalPalette(opts.Palette)))
+	}
+	return mappers
+}
+
+func remapOutputColors(styledText []*ansi.StyledText, mappers []colorMapper) {
+	if len(mappers) == 0 {
+		return
+	}
+
+	cache := make(map[*ansi.Col]*ansi.Col)
+	remap := func(col *ansi.Col) *ansi.Col {
+		if col == nil {
+			return nil
+		}
+		if mapped, ok := cache[col]; ok {
+			return mapped
+		}
+		r, g, b := col.Rgb.R, col.Rgb.G, col.Rgb.B
+		for _, mapper := range mappers {
+			r, g, b = mapper(r, g, b)
+		}
+		mapped := &ansi.Col{Id: 256, Hex: fmt.Sprintf("#%02x%02x%02x", r, g, b), Rgb: ansi.Rgb{R: r, G: g, B: b}}
+		cache[col] = mapped
+		return mapped
+	}
+
+	for _, block := range styledText {
+		if block == nil {
+			continue
+		}
+		block.FgCol = remap(block.FgCol)
+		block.BgCol = remap(block.BgCol)
+	}
+}
+
+func duotoneMapper(shadow, highlight [3]uint8) colorMapper {
+	return func(r, g, b uint8) (uint8, uint8, uint8) {
+		t := intensityOf([]uint8{r, g, b, 255}, LuminanceLuminosity)
+		lerp := func(a, b uint8) uint8 {
+			return clampUint8(float64(a) + (float64(b)-float64(a))*t)
+		}
+		return lerp(shadow[0], highlight[0]), lerp(shadow[1], highlight[1]), lerp(shadow[2], highlight[2])
+	}
+}
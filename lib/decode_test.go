@@ -0,0 +1,110 @@
+package lib
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+var ansiTrueColor = regexp.MustCompile(`^\x1b\[([34])8;2;(\d+);(\d+);(\d+)m`)
+
+func ansiCellColors(text string) [][6]int {
+	var cells [][6]int
+	var current [6]int
+	for len(text) > 0 {
+		if match := ansiTrueColor.FindStringSubmatch(text); match != nil {
+			offset := 0
+			if match[1] == "4" {
+				offset = 3
+			}
+			for i := 0; i < 3; i++ {
+				current[offset+i], _ = strconv.Atoi(match[i+2])
+			}
+			text = text[len(match[0]):]
+			continue
+		}
+		if text[0] == '\x1b' {
+			end := strings.IndexByte(text, 'm')
+			if end < 0 {
+				break
+			}
+			text = text[end+1:]
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(text)
+		if r != '\n' {
+			cells = append(cells, current)
+		}
+		text = text[size:]
+	}
+	return cells
+}
+
+func readFixture(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestDecodeJPEGVariants(t *testing.T) {
+	tests := []struct {
+		name      string
+		image     string
+		reference string
+	}{
+		{"adobe cmyk", "video-001.cmyk.jpeg", "video-001.cmyk.png"},
+		{"progressive", "video-001.progressive.jpeg", "video-001.png"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := DefaultOptions()
+			opts.Mode = ModeHalfBlock
+			opts.OutputFormat = OutputANSI
+			opts.TargetWidth = 24
+
+			got, err := ProcessImage(readFixture(t, tt.image), opts)
+			if err != nil {
+				t.Fatalf("ProcessImage(%s): %v", tt.image, err)
+			}
+			want, err := ProcessImage(readFixture(t, tt.reference), opts)
+			if err != nil {
+				t.Fatalf("ProcessImage(%s): %v", tt.reference, err)
+			}
+			if got.Format != "jpeg" {
+				t.Errorf("Format = %q, want jpeg", got.Format)
+			}
+			if got.ASCIIWidth != want.ASCIIWidth || got.ASCIIHeight != want.ASCIIHeight {
+				t.Fatalf("grid = %dx%d, want %dx%d", got.ASCIIWidth, got.ASCIIHeight, want.ASCIIWidth, want.ASCIIHeight)
+			}
+
+			gotCells, wantCells := ansiCellColors(got.Text), ansiCellColors(want.Text)
+			if len(gotCells) == 0 || len(gotCells) != len(wantCells) {
+				t.Fatalf("got %d cells, want %d", len(gotCells), len(wantCells))
+			}
+			total := 0
+			for i := range gotCells {
+				for c := range gotCells[i] {
+					total += abs(gotCells[i][c] - wantCells[i][c])
+				}
+			}
+			if mean := float64(total) / float64(6*len(gotCells)); mean > 2 {
+				t.Errorf("mean channel difference from %s = %.2f, want <= 2", tt.reference, mean)
+			}
+		})
+	}
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -0,0 +1,157 @@
+package lib
+
+import (
+	"image"
+	"image/draw"
+	"math"
+
+	"github.com/disintegration/imaging"
+)
+
+func isDeepImage(img image.Image) bool {
+	switch img.(type) {
+	case *image.NRGBA64, *image.RGBA64, *image.Gray16:
+		return true
+	}
+	return false
+}
+
+func toNRGBA64(img image.Image) *image.NRGBA64 {
+	if deep, ok := img.(*image.NRGBA64); ok {
+		return deep
+	}
+	bounds := img.Bounds()
+	dst := image.NewNRGBA64(bounds)
+	draw.Draw(dst, bounds, img, bounds.Min, draw.Src)
+	return dst
+}
+
+func adjustLevels16(img image.Image, brightness, contrast float64, linear bool) *image.NRGBA64 {
+	brightness = math.Min(math.Max(brightness, -100), 100)
+	contrast = math.Min(math.Max(contrast, -100), 100)
+	shift := brightness / 100
+	slope := (100 + contrast) / 100
+	if slope > 1 {
+		slope = 1 / math.Max(2-slope, 1e-6)
+	}
+
+	lut := make([]uint16, 65536)
+	for i := range lut {
+		v := float64(i) / 65535
+		if linear {
+			v = srgbToLinear(v)
+		}
+		v = clampFloat(0.5+(clampFloat(v+shift, 0, 1)-0.5)*slope, 0, 1)
+		if linear {
+			v = linearToSRGB(v)
+		}
+		lut[i] = uint16(math.Round(v * 65535))
+	}
+
+	src := toNRGBA64(img)
+	bounds := src.Bounds()
+	dst := image.NewNRGBA64(bounds)
+	for y := 0; y < bounds.Dy(); y++ {
+		srcRow := src.Pix[y*src.Stride : y*src.Stride+bounds.Dx()*8]
+		dstRow := dst.Pix[y*dst.Stride : y*dst.Stride+bounds.Dx()*8]
+		for i := 0; i < len(srcRow); i += 8 {
+			for c := 0; c < 3; c++ {
+				v := lut[uint16(srcRow[i+c*2])<<8|uint16(srcRow[i+c*2+1])]
+				dstRow[i+c*2] = uint8(v >> 8)
+				dstRow[i+c*2+1] = uint8(v)
+			}
+			dstRow[i+6] = srcRow[i+6]
+			dstRow[i+7] = srcRow[i+7]
+		}
+	}
+	return dst
+}
+
+type resampleWeight struct {
+	index  int
+	weight float64
+}
+
+func resampleWeights(dstSize, srcSize int, filter imaging.ResampleFilter) [][]resampleWeight {
+	du := float64(srcSize) / float64(dstSize)
+	scale := math.Max(du, 1)
+	weights := make([][]resampleWeight, dstSize)
+	for x := range weights {
+		center := (float64(x)+0.5)*du - 0.5
+		if filter.Support <= 0 {
+			weights[x] = []resampleWeight{{min(int((float64(x)+0.5)*du), srcSize-1), 1}}
+			continue
+		}
+
+		support := filter.Support * scale
+		begin := max(int(math.Ceil(center-support)), 0)
+		end := min(int(math.Floor(center+support)), srcSize-1)
+		sum := 0.0
+		for i := begin; i <= end; i++ {
+			if w := filter.Kernel((float64(i) - center) / scale); w != 0 {
+				weights[x] = append(weights[x], resampleWeight{i, w})
+				sum += w
+			}
+		}
+		if sum == 0 {
+			weights[x] = []resampleWeight{{min(max(int(math.Round(center)), 0), srcSize-1), 1}}
+			continue
+		}
+		for i := range weights[x] {
+			weights[x][i].weight /= sum
+		}
+	}
+	return weights
+}
+
+func resizeNRGBA64(img image.Image, width, height int, filter imaging.ResampleFilter) *image.NRGBA64 {
+	src := toNRGBA64(img)
+	bounds := src.Bounds()
+	horizontal := resamplePass64(src, width, bounds.Dy(), resampleWeights(width, bounds.Dx(), filter), true)
+	return resamplePass64(horizontal, width, height, resampleWeights(height, bounds.Dy(), filter), false)
+}
+
+func resamplePass64(src *image.NRGBA64, width, height int, weights [][]resampleWeight, horizontal bool) *image.NRGBA64 {
+	dst := image.NewNRGBA64(image.Rect(0, 0, width, height))
+	origin := src.Rect.Min
+	parallelRows(0, height, func(y0, y1 int) {
+		for y := y0; y < y1; y++ {
+			for x := 0; x < width; x++ {
+				var taps []resampleWeight
+				if horizontal {
+					taps = weights[x]
+				} else {
+					taps = weights[y]
+				}
+
+				var sum [4]float64
+				for _, tap := range taps {
+					sx, sy := x, tap.index
+					if horizontal {
+						sx, sy = tap.index, y
+					}
+					p := src.Pix[src.PixOffset(origin.X+sx, origin.Y+sy):]
+					alpha := float64(uint16(p[6])<<8|uint16(p[7])) * tap.weight
+					for c := 0; c < 3; c++ {
+						sum[c] += float64(uint16(p[c*2])<<8|uint16(p[c*2+1])) * alpha
+					}
+					sum[3] += alpha
+				}
+
+				q := dst.Pix[dst.PixOffset(x, y):]
+				if sum[3] <= 0 {
+					continue
+				}
+				for c := 0; c < 4; c++ {
+					v := sum[3]
+					if c < 3 {
+						v = sum[c] / sum[3]
+					}
+					u := uint16(clampFloat(math.Round(v), 0, 65535))
+					q[c*2], q[c*2+1] = uint8(u>>8), uint8(u)
+				}
+			}
+		}
+	})
+	return dst
+}
@@ -0,0 +1,71 @@
+package lib
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/disintegration/imaging"
+)
+
+func gradient64(width, height int) *image.NRGBA64 {
+	img := image.NewNRGBA64(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			v := uint16(x * 65535 / (width - 1))
+			img.SetNRGBA64(x, y, color.NRGBA64{R: v, G: v / 2, B: 65535 - v, A: 65535})
+		}
+	}
+	return img
+}
+
+func TestResizeNRGBA64MatchesImaging(t *testing.T) {
+	src := image.NewNRGBA(image.Rect(0, 0, 97, 61))
+	for i := range src.Pix {
+		src.Pix[i] = uint8(i * 37)
+	}
+	for _, name := range ResampleNames() {
+		t.Run(name, func(t *testing.T) {
+			filter := resampleFilter(name)
+			want := imaging.Resize(src, 31, 19, filter)
+			got := resizeNRGBA64(src, 31, 19, filter)
+			if got.Bounds() != want.Bounds() {
+				t.Fatalf("bounds = %v, want %v", got.Bounds(), want.Bounds())
+			}
+			for y := 0; y < 19; y++ {
+				for x := 0; x < 31; x++ {
+					g := color.NRGBAModel.Convert(got.NRGBA64At(x, y)).(color.NRGBA)
+					w := want.NRGBAAt(x, y)
+					if w.A == 0 {
+						continue
+					}
+					for _, d := range []int{int(g.R) - int(w.R), int(g.G) - int(w.G), int(g.B) - int(w.B), int(g.A) - int(w.A)} {
+						if abs(d) > 2 {
+							t.Fatalf("pixel (%d,%d) = %v, want %v", x, y, g, w)
+						}
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestDownscaleKeepsDeepImages(t *testing.T) {
+	img := downscaleImage(gradient64(2048, 4), 1024, ResampleLanczos)
+	deep, ok := img.(*image.NRGBA64)
+	if !ok {
+		t.Fatalf("downscaleImage returned %T, want *image.NRGBA64", img)
+	}
+	if deep.Bounds().Dx() != 1024 {
+		t.Fatalf("width = %d, want 1024", deep.Bounds().Dx())
+	}
+
+	adjusted := adjustLevels16(deep, 10, 20, false)
+	levels := make(map[uint16]bool)
+	for x := 0; x < adjusted.Bounds().Dx(); x++ {
+		levels[adjusted.NRGBA64At(x, 0).R] = true
+	}
+	if len(levels) <= 256 {
+		t.Errorf("adjusted gradient has %d distinct levels, want more than 256", len(levels))
+	}
+}
@@ -0,0 +1,19 @@
+package lib
+
+import "time"
+
+var deterministicEpoch = time.Unix(0, 0)
+
+func (o Options) now() time.Time {
+	if o.Deterministic {
+		return deterministicEpoch
+	}
+	return time.Now()
+}
+
+func (o Options) elapsedSince(start time.Time) time.Duration {
+	if o.Deterministic {
+		return 0
+	}
+	return time.Since(start)
+}
@@ -0,0 +1,92 @@
+package lib
+
+import (
+	"image"
+	"math"
+	"time"
+
+	"github.com/disintegration/imaging"
+)
+
+const (
+	defaultDiffThreshold = 0.1
+	defaultDiffColor     = "#FF0000"
+)
+
+func DiffImages(imageA, imageB []byte, opts Options) (*Result, error) {
+	start := time.Now()
+	if err := validateImageData(imageB); err != nil {
+		return nil, err
+	}
+	if err := validateInput(imageA, opts); err != nil {
+		return nil, err
+	}
+	if opts.OnChunk != nil {
+		return nil, NewOptionError("onChunk", "streaming output is not supported for image diffs")
+	}
+	opts.setDefaults()
+	for _, data := range [][]byte{imageA, imageB} {
+		if err := checkImageMemory(data, opts); err != nil {
+			return nil, err
+		}
+	}
+
+	opts.reportProgress(StageDecoding, 0)
+	a, format, err := decodeImage(imageA)
+	if err != nil {
+		return nil, err
+	}
+	b, _, err := decodeImage(imageB)
+	if err != nil {
+		return nil, err
+	}
+
+	opts.reportProgress(StageResizing, 20)
+	a = opts.downscale(a)
+	bounds := a.Bounds()
+	aligned := imaging.Resize(b, bounds.Dx(), bounds.Dy(), resampleFilter(opts.Resample))
+	diff := differenceImage(imaging.Clone(a), aligned)
+
+	changed := 0
+	highlight := hexToRGB(opts.DiffColor)
+	opts.gridFilter = func(grid Grid) {
+		if len(grid) == 0 {
+			return
+		}
+		fitted, _, _ := fitToGrid(transformImage(diff, opts), opts)
+		cells := imaging.Resize(fitted, len(grid[0]), len(grid), imaging.Box)
+		for y, row := range grid {
+			for x := range row {
+				if float64(cells.Pix[y*cells.Stride+x*4])/255 <= opts.DiffThreshold {
+					continue
+				}
+				row[x].BG = rgbColor(highlight[0], highlight[1], highlight[2])
+				changed++
+			}
+		}
+	}
+
+	result, err := renderImage(a, format, start, opts)
+	if err != nil {
+		return nil, err
+	}
+	result.ChangedCells = changed
+	return result, nil
+}
+
+func differenceImage(a, b *image.NRGBA) *image.Gray {
+	bounds := a.Bounds()
+	diff := image.NewGray(bounds)
+	for y := 0; y < bounds.Dy(); y++ {
+		rowA := a.Pix[y*a.Stride : y*a.Stride+bounds.Dx()*4]
+		rowB := b.Pix[y*b.Stride : y*b.Stride+bounds.Dx()*4]
+		for i := 0; i < len(rowA); i += 4 {
+			var maxDiff float64
+			for c := 0; c < 4; c++ {
+				maxDiff = math.Max(maxDiff, math.Abs(float64(rowA[i+c])-float64(rowB[i+c])))
+			}
+			diff.Pix[y*diff.Stride+i/4] = uint8(maxDiff)
+		}
+	}
+	return diff
+}
@@ -0,0 +1,74 @@
+package lib
+
+import "math"
+
+const (
+	DitherNone           = "none"
+	DitherFloydSteinberg = "floyd-steinberg"
+	DitherBayer          = "bayer"
+)
+
+var bayerMatrix = [8][8]float64{
+	{0, 32, 8, 40, 2, 34, 10, 42},
+	{48, 16, 56, 24, 50, 18, 58, 26},
+	{12, 44, 4, 36, 14, 46, 6, 38},
+	{60, 28, 52, 20, 62, 30, 54, 22},
+	{3, 35, 11, 43, 1, 33, 9, 41},
+	{51, 19, 59, 27, 49, 17, 57, 25},
+	{15, 47, 7, 39, 13, 45, 5, 37},
+	{63, 31, 55, 23, 61, 29, 53, 21},
+}
+
+func DitherNames() []string {
+	return []string{DitherNone, DitherFloydSteinberg, DitherBayer}
+}
+
+func applyDither(values []float64, width, height, levels int, method string) {
+	switch method {
+	case DitherFloydSteinberg:
+		ditherFloydSteinberg(values, width, height, levels)
+	case DitherBayer:
+		ditherBayer(values, width, height, levels)
+	}
+}
+
+func quantize(value float64, levels int) float64 {
+	steps := float64(levels - 1)
+	return math.Floor(math.Max(0, math.Min(1, value))*steps+0.5) / steps
+}
+
+func ditherFloydSteinberg(values []float64, width, height, levels int) {
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			i := y*width + x
+			old := values[i]
+			quantized := quantize(old, levels)
+			values[i] = quantized
+			diff := old - quantized
+
+			if x+1 < width {
+				values[i+1] += diff * 7 / 16
+			}
+			if y+1 < height {
+				if x > 0 {
+					values[i+width-1] += diff * 3 / 16
+				}
+				values[i+width] += diff * 5 / 16
+				if x+1 < width {
+					values[i+width+1] += diff * 1 / 16
+				}
+			}
+		}
+	}
+}
+
+func ditherBayer(values []float64, width, height, levels int) {
+	spread := 1 / float64(levels-1)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			i := y*width + x
+			offset := (bayerMatrix[y%8][x%8]+0.5)/64 - 0.5
+			values[i] = quantize(values[i]+offset*spread, levels)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package lib
+
+import (
+	"math"
+	"testing"
+)
+
+func TestApplyDither(t *testing.T) {
+	const width, height, levels = 16, 16, 4
+	tests := []struct {
+		method    string
+		quantized bool
+	}{
+		{DitherNone, false},
+		{DitherFloydSteinberg, true},
+		{DitherBayer, true},
+	}
+	if len(tests) != len(DitherNames()) {
+		t.Fatalf("table covers %d methods, DitherNames has %d", len(tests), len(DitherNames()))
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method, func(t *testing.T) {
+			values := make([]float64, width*height)
+			for i := range values {
+				values[i] = float64(i%width) / (width - 1)
+			}
+			original := append([]float64(nil), values...)
+			applyDither(values, width, height, levels, tt.method)
+
+			sum, originalSum := 0.0, 0.0
+			for i, v := range values {
+				sum += v
+				originalSum += original[i]
+				if !tt.quantized {
+					if v != original[i] {
+						t.Fatalf("value %d changed from %v to %v", i, original[i], v)
+					}
+					continue
+				}
+				if steps := v * (levels - 1); math.Abs(steps-math.Round(steps)) > 1e-9 {
+					t.Fatalf("value %d = %v is not one of %d levels", i, v, levels)
+				}
+			}
+			if mean, want := sum/float64(len(values)), originalSum/float64(len(values)); math.Abs(mean-want) > 0.05 {
+				t.Errorf("mean = %.3f, want about %.3f", mean, want)
+			}
+		})
+	}
+}
+
+func TestProcessImageDither(t *testing.T) {
+	image := readFixture(t, "video-001.png")
+	for _, method := range DitherNames() {
+		t.Run(method, func(t *testing.T) {
+			opts := DefaultOptions()
+			opts.OutputFormat = OutputText
+			opts.TargetWidth = 24
+			opts.Dither = method
+			if _, err := ProcessImage(image, opts); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package lib
+
+import (
+	"fmt"
+	"sort"
+)
+
+const (
+	MaxExtractColors        = 64
+	dominantSampleDimension = 256
+)
+
+func ExtractPalette(imageData []byte, n int) ([]string, error) {
+	if err := validateImageData(imageData); err != nil {
+		return nil, err
+	}
+	if n < 1 || n > MaxExtractColors {
+		return nil, NewError(CodeBadInput, "color count must be between 1 and %d, got %d", MaxExtractColors, n)
+	}
+
+	img, _, err := decodeImage(imageData)
+	if err != nil {
+		return nil, err
+	}
+	sample := cloneNRGBA(downscaleImage(img, dominantSampleDimension, ResampleBox))
+	defer releaseNRGBA(sample)
+	palette := medianCutPalette(sample, n)
+
+	counts := make([]int, len(palette))
+	for i := 0; i < len(sample.Pix); i += 4 {
+		p := sample.Pix[i : i+4]
+		if p[3] == 0 {
+			continue
+		}
+		counts[nearestPaletteIndex(palette, [3]uint8{p[0], p[1], p[2]})]++
+	}
+
+	order := make([]int, len(palette))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return counts[order[a]] > counts[order[b]] })
+
+	colors := make([]string, 0, len(palette))
+	seen := make(map[string]bool, len(palette))
+	for _, i := range order {
+		c := palette[i]
+		hex := fmt.Sprintf("#%02x%02x%02x", c[0], c[1], c[2])
+		if !seen[hex] {
+			seen[hex] = true
+			colors = append(colors, hex)
+		}
+	}
+	return colors, nil
+}
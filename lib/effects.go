@@ -0,0 +1,39 @@
+package lib
+
+import (
+	"cmp"
+	"fmt"
+
+	"github.com/ajstarks/svgo"
+)
+
+const (
+	textShadowID     = "shadow"
+	maxOutlineWidth  = 8
+	maxShadowOffset  = 32
+	maxShadowBlur    = 32
+	defaultShadowGap = 1
+)
+
+func textOutlineCSS(opts Options) string {
+	if opts.OutlineWidth <= 0 {
+		return ""
+	}
+	outline := cmp.Or(opts.OutlineColor, opts.BackgroundColor)
+	return fmt.Sprintf(";stroke:%s;stroke-width:%gpx;stroke-linejoin:round;paint-order:stroke", outline, opts.OutlineWidth)
+}
+
+func writeTextShadowFilter(canvas *svg.SVG, opts Options) bool {
+	if opts.ShadowColor == "" {
+		return false
+	}
+	offset := opts.ShadowOffset
+	if offset == 0 && opts.ShadowBlur == 0 {
+		offset = defaultShadowGap
+	}
+	canvas.Def()
+	fmt.Fprintf(canvas.Writer, `<filter id="%s" x="-10%%" y="-10%%" width="120%%" height="120%%"><feDropShadow dx="%g" dy="%g" stdDeviation="%g" flood-color="%s"/></filter>`+"\n",
+		textShadowID, offset, offset, opts.ShadowBlur, opts.ShadowColor)
+	canvas.DefEnd()
+	return true
+}
@@ -0,0 +1,80 @@
+package lib
+
+import (
+	"image"
+
+	"github.com/disintegration/imaging"
+)
+
+const (
+	ModeEmoji     = "emoji"
+	emojiCellSize = 20
+)
+
+var emojiColors = []struct {
+	char  rune
+	color [3]uint8
+}{
+	{'🟥', [3]uint8{221, 46, 68}},
+	{'🟧', [3]uint8{244, 144, 12}},
+	{'🟨', [3]uint8{253, 203, 88}},
+	{'🟩', [3]uint8{120, 177, 89}},
+	{'🟦', [3]uint8{85, 172, 238}},
+	{'🟪', [3]uint8{170, 142, 214}},
+	{'🟫', [3]uint8{193, 105, 79}},
+	{'⬛', [3]uint8{49, 55, 61}},
+	{'⬜', [3]uint8{230, 231, 232}},
+	{'🍎', [3]uint8{190, 25, 49}},
+	{'🍷', [3]uint8{160, 4, 30}},
+	{'🌺', [3]uint8{234, 89, 110}},
+	{'🌸', [3]uint8{244, 171, 186}},
+	{'🍑', [3]uint8{255, 136, 108}},
+	{'🍯', [3]uint8{255, 172, 51}},
+	{'🍋', [3]uint8{255, 220, 93}},
+	{'🍞', [3]uint8{226, 168, 120}},
+	{'🍪', [3]uint8{217, 158, 130}},
+	{'🌰', [3]uint8{138, 75, 56}},
+	{'🍫', [3]uint8{102, 33, 19}},
+	{'🌿', [3]uint8{92, 145, 59}},
+	{'🌲', [3]uint8{62, 114, 29}},
+	{'🧊', [3]uint8{187, 221, 245}},
+	{'🌊', [3]uint8{59, 136, 195}},
+	{'🍇', [3]uint8{116, 78, 170}},
+	{'🌌', [3]uint8{40, 48, 82}},
+	{'🐘', [3]uint8{153, 170, 181}},
+	{'🌑', [3]uint8{102, 117, 127}},
+}
+
+var emojiPalette = func() [][3]uint8 {
+	palette := make([][3]uint8, len(emojiColors))
+	for i, emoji := range emojiColors {
+		palette[i] = emoji.color
+	}
+	return palette
+}()
+
+func renderEmoji(img image.Image, width, height int, opts Options) Grid {
+	resized := imaging.Resize(img, width, height, resampleFilter(opts.Resample))
+
+	grid := newGrid(width, height)
+	for y := 0; y < height; y++ {
+		row := resized.Pix[y*resized.Stride:]
+		for x := 0; x < width; x++ {
+			emoji := emojiColors[nearestPaletteIndex(emojiPalette, [3]uint8{row[x*4], row[x*4+1], row[x*4+2]})]
+			grid[y][x] = Cell{
+				Char: emoji.char,
+				FG:   rgbColor(emoji.color[0], emoji.color[1], emoji.color[2]),
+			}
+		}
+	}
+	return grid
+}
+
+func emojiColor(char rune) ([3]uint8, bool) {
+	for _, emoji := range emojiColors {
+		if emoji.char == char {
+			return emoji.color, true
+		}
+	}
+	return [3]uint8{}, false
+}
@@ -0,0 +1,76 @@
+package lib
+
+import (
+	"errors"
+	"fmt"
+)
+
+type ErrorCode string
+
+const (
+	CodeBadInput  ErrorCode = "ERR_BAD_INPUT"
+	CodeBadOption ErrorCode = "ERR_BAD_OPTION"
+	CodeDecode    ErrorCode = "ERR_DECODE"
+	CodeTooLarge  ErrorCode = "ERR_TOO_LARGE"
+	CodeConvert   ErrorCode = "ERR_CONVERT"
+	CodeRender    ErrorCode = "ERR_RENDER"
+	CodeInternal  ErrorCode = "ERR_INTERNAL"
+)
+
+type Error struct {
+	Code    ErrorCode
+	Message string
+	Option  string
+	Limit   int
+	Actual  int
+	Err     error
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+func NewError(code ErrorCode, format string, args ...any) *Error {
+	wrapped := fmt.Errorf(format, args...)
+	return &Error{Code: code, Message: wrapped.Error(), Err: errors.Unwrap(wrapped)}
+}
+
+func NewOptionError(option string, format string, args ...any) *Error {
+	err := NewError(CodeBadOption, format, args...)
+	err.Option = option
+	return err
+}
+
+func newLimitError(actual, limit int, format string, args ...any) *Error {
+	err := NewError(CodeTooLarge, format, args...)
+	err.Actual = actual
+	err.Limit = limit
+	return err
+}
+
+func ErrorFields(err error) map[string]any {
+	fields := map[string]any{"code": string(CodeOf(err)), "message": err.Error()}
+	var libErr *Error
+	if errors.As(err, &libErr) {
+		if libErr.Option != "" {
+			fields["option"] = libErr.Option
+		}
+		if libErr.Limit > 0 {
+			fields["limit"] = libErr.Limit
+			fields["actual"] = libErr.Actual
+		}
+	}
+	return fields
+}
+
+func CodeOf(err error) ErrorCode {
+	var libErr *Error
+	if errors.As(err, &libErr) {
+		return libErr.Code
+	}
+	return CodeInternal
+}
@@ -0,0 +1,83 @@
+package lib
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestProcessImageErrorCodes(t *testing.T) {
+	image := readFixture(t, "video-001.png")
+	tests := []struct {
+		name       string
+		data       []byte
+		setup      func(opts *Options)
+		limits     Limits
+		wantCode   ErrorCode
+		wantOption string
+	}{
+		{name: "empty input", data: nil, wantCode: CodeBadInput},
+		{name: "not an image", data: []byte("not an image"), wantCode: CodeDecode},
+		{name: "unknown mode", data: image, setup: func(o *Options) { o.Mode = "sketch" }, wantCode: CodeBadOption, wantOption: "mode"},
+		{name: "rotation out of range", data: image, setup: func(o *Options) { o.Rotate = 400 }, wantCode: CodeBadOption, wantOption: "rotate"},
+		{name: "unknown output format", data: image, setup: func(o *Options) { o.OutputFormat = "bmp" }, wantCode: CodeBadOption, wantOption: "outputFormat"},
+		{name: "too many characters", data: image, setup: func(o *Options) { o.TargetWidth = 400 }, limits: Limits{MaxASCIIChars: 10_000}, wantCode: CodeTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.limits != (Limits{}) {
+				saved := CurrentLimits()
+				t.Cleanup(func() { ConfigureLimits(saved) })
+				if _, err := ConfigureLimits(tt.limits); err != nil {
+					t.Fatal(err)
+				}
+			}
+			opts := DefaultOptions()
+			if tt.setup != nil {
+				tt.setup(&opts)
+			}
+
+			_, err := ProcessImage(tt.data, opts)
+			if code := CodeOf(err); code != tt.wantCode {
+				t.Fatalf("CodeOf(%v) = %s, want %s", err, code, tt.wantCode)
+			}
+			var libErr *Error
+			if !errors.As(err, &libErr) {
+				t.Fatalf("err = %T, want *Error", err)
+			}
+			if libErr.Option != tt.wantOption {
+				t.Errorf("Option = %q, want %q", libErr.Option, tt.wantOption)
+			}
+			if tt.wantCode == CodeTooLarge && (libErr.Limit <= 0 || libErr.Actual <= libErr.Limit) {
+				t.Errorf("Limit = %d, Actual = %d, want Actual above a positive Limit", libErr.Limit, libErr.Actual)
+			}
+		})
+	}
+}
+
+func TestErrorFields(t *testing.T) {
+	limitErr := newLimitError(20, 10, "too large")
+	tests := []struct {
+		name string
+		err  error
+		want map[string]any
+	}{
+		{"plain error", errors.New("boom"), map[string]any{"code": "ERR_INTERNAL", "message": "boom"}},
+		{"option error", NewOptionError("mode", "bad mode"), map[string]any{"code": "ERR_BAD_OPTION", "message": "bad mode", "option": "mode"}},
+		{"limit error", limitErr, map[string]any{"code": "ERR_TOO_LARGE", "message": "too large", "limit": 10, "actual": 20}},
+		{"wrapped", fmt.Errorf("outer: %w", limitErr), map[string]any{"code": "ERR_TOO_LARGE", "message": "outer: too large", "limit": 10, "actual": 20}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ErrorFields(tt.err)
+			if len(got) != len(tt.want) {
+				t.Fatalf("ErrorFields = %v, want %v", got, tt.want)
+			}
+			for key, value := range tt.want {
+				if got[key] != value {
+					t.Errorf("%s = %v, want %v", key, got[key], value)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,230 @@
+package lib
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/disintegration/imaging"
+)
+
+func shiftHue(img image.Image, degrees float64) image.Image {
+	shift := math.Mod(degrees, 360) / 360
+	return imaging.AdjustFunc(img, func(c color.NRGBA) color.NRGBA {
+		h, s, l := rgbToHSL(c.R, c.G, c.B)
+		h = math.Mod(h+shift+1, 1)
+		r, g, b := hslToRGB(h, s, l)
+		return color.NRGBA{R: r, G: g, B: b, A: c.A}
+	})
+}
+
+const (
+	defaultChromaKeyTolerance = 0.1
+	defaultAutoLevelsClip     = 0.5
+	maxAutoLevelsClip         = 25
+	chromaKeyFeather          = 0.5
+)
+
+func chromaKey(img image.Image, key [3]uint8, tolerance float64) image.Image {
+	maxDistance := math.Sqrt(3 * 255 * 255)
+	feather := tolerance * chromaKeyFeather
+	return imaging.AdjustFunc(img, func(c color.NRGBA) color.NRGBA {
+		d := math.Sqrt(float64(colorDistance([3]uint8{c.R, c.G, c.B}, key))) / maxDistance
+		switch {
+		case d <= tolerance:
+			c.A = 0
+		case d < tolerance+feather:
+			c.A = uint8(float64(c.A) * (d - tolerance) / feather)
+		}
+		return c
+	})
+}
+
+const toneCurveSize = 256
+
+func applyToneCurve(img image.Image, points []float64) image.Image {
+	var lut [toneCurveSize]uint8
+	segments := float64(len(points) - 1)
+	for i := range lut {
+		pos := float64(i) / (toneCurveSize - 1) * segments
+		j := min(int(pos), len(points)-2)
+		v := points[j] + (points[j+1]-points[j])*(pos-float64(j))
+		lut[i] = clampUint8(v * 255)
+	}
+	return imaging.AdjustFunc(img, func(c color.NRGBA) color.NRGBA {
+		return color.NRGBA{R: lut[c.R], G: lut[c.G], B: lut[c.B], A: c.A}
+	})
+}
+
+func rgbToHSL(r, g, b uint8) (float64, float64, float64) {
+	rf, gf, bf := float64(r)/255, float64(g)/255, float64(b)/255
+	maxC := math.Max(rf, math.Max(gf, bf))
+	minC := math.Min(rf, math.Min(gf, bf))
+	l := (maxC + minC) / 2
+	if maxC == minC {
+		return 0, 0, l
+	}
+
+	d := maxC - minC
+	s := d / (maxC + minC)
+	if l > 0.5 {
+		s = d / (2 - maxC - minC)
+	}
+
+	var h float64
+	switch maxC {
+	case rf:
+		h = (gf - bf) / d
+		if gf < bf {
+			h += 6
+		}
+	case gf:
+		h = (bf-rf)/d + 2
+	default:
+		h = (rf-gf)/d + 4
+	}
+	return h / 6, s, l
+}
+
+func hslToRGB(h, s, l float64) (uint8, uint8, uint8) {
+	if s == 0 {
+		v := clampUint8(l * 255)
+		return v, v, v
+	}
+
+	q := l * (1 + s)
+	if l >= 0.5 {
+		q = l + s - l*s
+	}
+	p := 2*l - q
+	r := hueToRGB(p, q, h+1.0/3)
+	g := hueToRGB(p, q, h)
+	b := hueToRGB(p, q, h-1.0/3)
+	return clampUint8(r * 255), clampUint8(g * 255), clampUint8(b * 255)
+}
+
+func hueToRGB(p, q, t float64) float64 {
+	if t < 0 {
+		t++
+	}
+	if t > 1 {
+		t--
+	}
+	switch {
+	case t < 1.0/6:
+		return p + (q-p)*6*t
+	case t < 0.5:
+		return q
+	case t < 2.0/3:
+		return p + (q-p)*(2.0/3-t)*6
+	default:
+		return p
+	}
+}
+
+func clampUint8(v float64) uint8 {
+	return uint8(math.Max(0, math.Min(255, math.Round(v))))
+}
+
+func equalizeHistogram(img image.Image) image.Image {
+	histogram := imaging.Histogram(img)
+
+	var cdf [256]float64
+	sum := 0.0
+	for i, v := range histogram {
+		sum += v
+		cdf[i] = sum
+	}
+
+	cdfMin := 0.0
+	for _, v := range cdf {
+		if v > 0 {
+			cdfMin = v
+			break
+		}
+	}
+	if cdfMin >= 1 {
+		return img
+	}
+
+	var lut [256]uint8
+	for i, v := range cdf {
+		lut[i] = clampUint8((v - cdfMin) / (1 - cdfMin) * 255)
+	}
+	return applyLUT(img, lut)
+}
+
+func stretchLevels(img image.Image, clipPercent float64) image.Image {
+	histogram := imaging.Histogram(img)
+	clip := clipPercent / 100
+
+	low, sum := 0, 0.0
+	for ; low < 255; low++ {
+		if sum += histogram[low]; sum > clip {
+			break
+		}
+	}
+	high := 255
+	for sum = 0; high > low; high-- {
+		if sum += histogram[high]; sum > clip {
+			break
+		}
+	}
+	if high <= low || (low == 0 && high == 255) {
+		return img
+	}
+
+	var lut [256]uint8
+	for i := range lut {
+		lut[i] = clampUint8(float64(i-low) / float64(high-low) * 255)
+	}
+	return applyLUT(img, lut)
+}
+
+func applyLUT(img image.Image, lut [256]uint8) image.Image {
+	return imaging.AdjustFunc(img, func(c color.NRGBA) color.NRGBA {
+		return color.NRGBA{R: lut[c.R], G: lut[c.G], B: lut[c.B], A: c.A}
+	})
+}
+
+func unsharpMask(img image.Image, radius, amount, threshold float64) image.Image {
+	src := imaging.Clone(img)
+	blurred := imaging.Blur(src, radius)
+	for i := 0; i < len(src.Pix); i += 4 {
+		for c := 0; c < 3; c++ {
+			diff := float64(src.Pix[i+c]) - float64(blurred.Pix[i+c])
+			if math.Abs(diff) < threshold {
+				continue
+			}
+			src.Pix[i+c] = clampUint8(float64(src.Pix[i+c]) + diff*amount)
+		}
+	}
+	return src
+}
+
+func transformImage(img image.Image, opts Options) image.Image {
+	if opts.FlipHorizontal {
+		img = imaging.FlipH(img)
+	}
+	if opts.FlipVertical {
+		img = imaging.FlipV(img)
+	}
+
+	angle := math.Mod(math.Mod(opts.Rotate, 360)+360, 360)
+	switch angle {
+	case 0:
+		return img
+	case 90:
+		return imaging.Rotate270(img)
+	case 180:
+		return imaging.Rotate180(img)
+	case 270:
+		return imaging.Rotate90(img)
+	}
+
+	var fill color.Color = color.Transparent
+	if c := parseHexColor(opts.RotateFill); c != nil {
+		fill = c
+	}
+	return imaging.Rotate(img, -angle, fill)
+}
@@ -0,0 +1,68 @@
+package lib
+
+import (
+	"image"
+
+	"github.com/disintegration/imaging"
+)
+
+const (
+	FitContain = "contain"
+	FitCover   = "cover"
+	FitStretch = "stretch"
+)
+
+func FitNames() []string {
+	return []string{FitContain, FitCover, FitStretch}
+}
+
+func fitToGrid(img image.Image, opts Options) (image.Image, int, int) {
+	bounds := img.Bounds()
+	srcWidth, srcHeight := bounds.Dx(), bounds.Dy()
+	width, height := gridSize(srcWidth, srcHeight, opts)
+	if opts.TargetHeight <= 0 || opts.Fit != FitCover {
+		return img, width, height
+	}
+
+	cropWidth := srcWidth
+	cropHeight := int(float64(srcWidth) * float64(height) / float64(width))
+	if cropHeight > srcHeight {
+		cropHeight = srcHeight
+		cropWidth = int(float64(srcHeight) * float64(width) / float64(height))
+	}
+	return imaging.CropCenter(img, max(cropWidth, 1), max(cropHeight, 1)), width, height
+}
+
+func gridSize(srcWidth, srcHeight int, opts Options) (int, int) {
+	aspectRatio := float64(srcHeight) / float64(srcWidth)
+	width := opts.TargetWidth
+	height := max(int(float64(width)*aspectRatio), 1)
+	if opts.TargetHeight <= 0 {
+		return width, height
+	}
+
+	switch opts.Fit {
+	case FitStretch, FitCover:
+		return width, opts.TargetHeight
+	default:
+		if height > opts.TargetHeight {
+			height = opts.TargetHeight
+			width = max(int(float64(height)/aspectRatio), 1)
+		}
+		return width, height
+	}
+}
+
+func clampGrid(width, height int, limits Limits) (int, int) {
+	if width > limits.MaxASCIIDimension {
+		scale := float64(limits.MaxASCIIDimension) / float64(width)
+		width = limits.MaxASCIIDimension
+		height = max(int(float64(height)*scale), 1)
+	}
+	if height > limits.MaxASCIIDimension {
+		scale := float64(limits.MaxASCIIDimension) / float64(height)
+		height = limits.MaxASCIIDimension
+		width = max(int(float64(width)*scale), 1)
+	}
+	return width, height
+}
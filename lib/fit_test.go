@@ -0,0 +1,54 @@
+package lib
+
+import "testing"
+
+func TestClampGrid(t *testing.T) {
+	limits := Limits{MaxASCIIDimension: 500}
+	tests := []struct {
+		name                  string
+		width, height         int
+		wantWidth, wantHeight int
+	}{
+		{"within limits", 120, 80, 120, 80},
+		{"at limit", 500, 500, 500, 500},
+		{"wide", 1000, 300, 500, 150},
+		{"tall", 300, 1000, 150, 500},
+		{"both too large", 2000, 1000, 500, 250},
+		{"extreme height", 500, 500000, 1, 500},
+		{"extreme width", 500000, 500, 500, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			width, height := clampGrid(tt.width, tt.height, limits)
+			if width != tt.wantWidth || height != tt.wantHeight {
+				t.Errorf("clampGrid(%d, %d) = %dx%d, want %dx%d", tt.width, tt.height, width, height, tt.wantWidth, tt.wantHeight)
+			}
+		})
+	}
+}
+
+func TestGridSize(t *testing.T) {
+	tests := []struct {
+		name                  string
+		fit                   string
+		targetWidth           int
+		targetHeight          int
+		wantWidth, wantHeight int
+	}{
+		{"width only", FitContain, 100, 0, 100, 50},
+		{"contain within height", FitContain, 100, 80, 100, 50},
+		{"contain limited by height", FitContain, 100, 25, 50, 25},
+		{"stretch", FitStretch, 100, 25, 100, 25},
+		{"cover", FitCover, 100, 80, 100, 80},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := DefaultOptions()
+			opts.Fit, opts.TargetWidth, opts.TargetHeight = tt.fit, tt.targetWidth, tt.targetHeight
+			width, height := gridSize(200, 100, opts)
+			if width != tt.wantWidth || height != tt.wantHeight {
+				t.Errorf("gridSize = %dx%d, want %dx%d", width, height, tt.wantWidth, tt.wantHeight)
+			}
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package lib
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+)
+
+const embeddedFontFamily = "ascii-embedded"
+
+func embeddedFontFormat(data []byte) (mime, format string, ok bool) {
+	switch {
+	case bytes.HasPrefix(data, []byte("wOFF")):
+		return "font/woff", "woff", true
+	case bytes.HasPrefix(data, []byte("wOF2")):
+		return "font/woff2", "woff2", true
+	case bytes.HasPrefix(data, []byte("OTTO")):
+		return "font/otf", "opentype", true
+	case bytes.HasPrefix(data, []byte{0x00, 0x01, 0x00, 0x00}), bytes.HasPrefix(data, []byte("true")):
+		return "font/ttf", "truetype", true
+	}
+	return "", "", false
+}
+
+func embeddedFontSize(data []byte) int {
+	return base64.StdEncoding.EncodedLen(len(data))
+}
+
+func fontFaceCSS(data []byte) string {
+	mime, format, _ := embeddedFontFormat(data)
+	return fmt.Sprintf("@font-face{font-family:'%s';src:url(data:%s;base64,%s) format('%s')}",
+		embeddedFontFamily, mime, base64.StdEncoding.EncodeToString(data), format)
+}
@@ -0,0 +1,102 @@
+package lib
+
+import (
+	"image"
+	"time"
+)
+
+const MaxFrames = 1000
+
+func ProcessFrames(frames [][]byte, width, height int, opts Options) ([]*Result, error) {
+	start := time.Now()
+	opts, err := prepareFrames(frames, width, height, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	frameOpts := opts
+	frameOpts.Progress = nil
+	results := make([]*Result, 0, len(frames))
+	err = eachFrame(frames, width, height, opts, func(img image.Image) error {
+		result, err := renderImage(img, "rgba", start, frameOpts)
+		if err != nil {
+			return err
+		}
+		results = append(results, result)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+func ValidateFrames(count, width, height int) error {
+	if count == 0 || count > MaxFrames {
+		return NewError(CodeBadInput, "expected between 1 and %d frames, got %d", MaxFrames, count)
+	}
+	if width <= 0 || height <= 0 {
+		return NewError(CodeBadInput, "invalid frame dimensions: %dx%d", width, height)
+	}
+	if err := ValidateImagePixels(width, height); err != nil {
+		return err
+	}
+	frameBytes := width * height * 4
+	if budget := CurrentLimits().MaxMemory; count > budget/frameBytes {
+		return newLimitError(count, budget/frameBytes, "%d frames of %dx%d pixels exceed the memory budget of %s bytes (max: %d frames)",
+			count, width, height, formatNumber(budget), budget/frameBytes)
+	}
+	return nil
+}
+
+func ValidateFrameLength(index, length, width, height int) error {
+	if length != width*height*4 {
+		return NewError(CodeBadInput, "frame %d pixel data length mismatch: got %d bytes, expected %d for %dx%d RGBA", index, length, width*height*4, width, height)
+	}
+	return nil
+}
+
+func prepareFrames(frames [][]byte, width, height int, opts Options) (Options, error) {
+	if err := ValidateFrames(len(frames), width, height); err != nil {
+		return opts, err
+	}
+	if opts.OnChunk != nil {
+		return opts, NewOptionError("onChunk", "streaming output is not supported when rendering frames")
+	}
+	if opts.OnLine != nil {
+		return opts, NewOptionError("onLine", "line callbacks are not supported when rendering frames")
+	}
+	for i, pixels := range frames {
+		if err := ValidateFrameLength(i, len(pixels), width, height); err != nil {
+			return opts, err
+		}
+	}
+	if err := validateInput(frames[0], opts); err != nil {
+		return opts, err
+	}
+	opts.setDefaults()
+	if err := checkMemoryBudget(len(frames)*width*height*4 + estimateMemory(width, height, 4, false, opts)); err != nil {
+		return opts, err
+	}
+
+	converter, err := opts.resolveConverter()
+	if err != nil {
+		return opts, err
+	}
+	limits := opts.limits()
+	opts.converter, opts.frameLimits = converter, &limits
+	return opts, nil
+}
+
+func eachFrame(frames [][]byte, width, height int, opts Options, render func(img image.Image) error) error {
+	img := &image.NRGBA{Stride: width * 4, Rect: image.Rect(0, 0, width, height)}
+	for i, pixels := range frames {
+		img.Pix = pixels
+		if err := render(opts.downscale(img)); err != nil {
+			return err
+		}
+		opts.reportProgress(StageRendering, 100*float64(i+1)/float64(len(frames)))
+	}
+	opts.reportProgress(StageDone, 100)
+	return nil
+}
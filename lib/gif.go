@@ -0,0 +1,53 @@
+package lib
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/color/palette"
+	"image/draw"
+	"image/gif"
+
+	"github.com/leaanthony/go-ansi-parser"
+)
+
+const (
+	defaultFrameDelay = 100
+	minFrameDelay     = 10
+	maxFrameDelay     = 10000
+)
+
+func renderToGIF(styledText []*ansi.StyledText, opts Options) ([]byte, error) {
+	img, err := rasterize(styledText, opts)
+	if err != nil {
+		return nil, err
+	}
+	return encodeGIF([]*image.Paletted{palettize(img)}, opts.FrameDelay)
+}
+
+func palettize(img *image.NRGBA) *image.Paletted {
+	paletted := image.NewPaletted(img.Rect, palette.Plan9)
+	draw.Draw(paletted, paletted.Rect, img, img.Rect.Min, draw.Src)
+	return paletted
+}
+
+func encodeGIF(frames []*image.Paletted, frameDelay int) ([]byte, error) {
+	anim := &gif.GIF{
+		Image: frames,
+		Delay: make([]int, len(frames)),
+		Config: image.Config{
+			ColorModel: color.Palette(palette.Plan9),
+		},
+	}
+	for i, frame := range frames {
+		anim.Delay[i] = frameDelay / 10
+		anim.Config.Width = max(anim.Config.Width, frame.Rect.Dx())
+		anim.Config.Height = max(anim.Config.Height, frame.Rect.Dy())
+	}
+
+	var buffer bytes.Buffer
+	if err := gif.EncodeAll(&buffer, anim); err != nil {
+		return nil, NewError(CodeRender, "failed to encode GIF: %w", err)
+	}
+	return buffer.Bytes(), nil
+}
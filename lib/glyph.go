@@ -0,0 +1,140 @@
+package lib
+
+import "github.com/leaanthony/go-ansi-parser"
+
+var shadeLevels = map[rune]float64{'░': 0.25, '▒': 0.5, '▓': 0.75}
+
+var pointLevels = map[rune]float64{'·': 0.04, '∙': 0.08, '•': 0.16, '●': 0.5}
+
+type boxSegments struct {
+	up, down, left, right, heavy bool
+}
+
+var boxDrawing = map[rune]boxSegments{
+	'─': {left: true, right: true},
+	'│': {up: true, down: true},
+	'┌': {down: true, right: true},
+	'┐': {down: true, left: true},
+	'└': {up: true, right: true},
+	'┘': {up: true, left: true},
+	'━': {left: true, right: true, heavy: true},
+	'┃': {up: true, down: true, heavy: true},
+	'┏': {down: true, right: true, heavy: true},
+	'┓': {down: true, left: true, heavy: true},
+	'┗': {up: true, right: true, heavy: true},
+	'┛': {up: true, left: true, heavy: true},
+}
+
+type cellPainter interface {
+	fillRect(c [3]uint8, x, y, width, height float64)
+	drawText(char rune, c [3]uint8, left, top float64)
+	fillCircle(c [3]uint8, centerX, centerY, radius float64)
+}
+
+func paintCells(p cellPainter, lines [][]*ansi.StyledText, m cellMetrics, background [3]uint8, fallbackText string) {
+	for row, line := range lines {
+		top := float64(row * m.lineHeight)
+		col := 0
+		for _, styledChar := range line {
+			cellBackground := background
+			if styledChar.BgCol != nil && styledChar.BgCol.Hex != "" {
+				cellBackground = hexToRGB(styledChar.BgCol.Hex)
+			}
+			foreground := hexToRGB(textColorOf(styledChar, fallbackText))
+
+			for _, char := range styledChar.Label {
+				left := float64(col * m.charWidth)
+				cell := m
+				cell.charWidth *= runeWidth(char)
+				if cellBackground != background {
+					p.fillRect(cellBackground, left, top, float64(cell.charWidth), float64(m.lineHeight))
+				}
+				if char != ' ' && !styledChar.Invisible() {
+					paintGlyph(p, char, foreground, cellBackground, left, top, cell)
+				}
+				col += runeWidth(char)
+			}
+		}
+	}
+}
+
+func paintGlyph(p cellPainter, char rune, fg, bg [3]uint8, left, top float64, m cellMetrics) {
+	cellWidth, cellHeight := float64(m.charWidth), float64(m.lineHeight)
+
+	if level, ok := m.dots[char]; ok {
+		if level > 0 {
+			p.fillCircle(fg, left+cellWidth/2, top+cellHeight/2, dotRadius(level, m))
+		}
+		return
+	}
+	if level, ok := pointLevels[char]; ok {
+		p.fillCircle(fg, left+cellWidth/2, top+cellHeight/2, dotRadius(level, m))
+		return
+	}
+
+	if mask := quadrantMask(char); mask > 0 {
+		halfWidth, halfHeight := cellWidth/2, cellHeight/2
+		for bit := 0; bit < 4; bit++ {
+			if mask&(1<<bit) != 0 {
+				p.fillRect(fg, left+float64(bit%2)*halfWidth, top+float64(bit/2)*halfHeight, halfWidth, halfHeight)
+			}
+		}
+		return
+	}
+	if box, ok := boxDrawing[char]; ok {
+		thickness := max(min(cellWidth, cellHeight)/8, 1)
+		if box.heavy {
+			thickness *= 2
+		}
+		centerX, centerY := left+cellWidth/2, top+cellHeight/2
+		if box.left {
+			p.fillRect(fg, left, centerY-thickness/2, cellWidth/2+thickness/2, thickness)
+		}
+		if box.right {
+			p.fillRect(fg, centerX-thickness/2, centerY-thickness/2, cellWidth/2+thickness/2, thickness)
+		}
+		if box.up {
+			p.fillRect(fg, centerX-thickness/2, top, thickness, cellHeight/2+thickness/2)
+		}
+		if box.down {
+			p.fillRect(fg, centerX-thickness/2, centerY-thickness/2, thickness, cellHeight/2+thickness/2)
+		}
+		return
+	}
+	if level, ok := shadeLevels[char]; ok {
+		var shaded [3]uint8
+		for c := range shaded {
+			shaded[c] = clampUint8(float64(bg[c]) + (float64(fg[c])-float64(bg[c]))*level)
+		}
+		p.fillRect(shaded, left, top, cellWidth, cellHeight)
+		return
+	}
+	if color, ok := emojiColor(char); ok {
+		inset := min(cellWidth, cellHeight) / 10
+		p.fillRect(color, left+inset, top+inset, cellWidth-2*inset, cellHeight-2*inset)
+		return
+	}
+	if char >= brailleBase && char <= brailleBase+0xFF {
+		dotWidth, dotHeight := cellWidth/2, cellHeight/4
+		size := min(dotWidth, dotHeight) * 0.6
+		for row := 0; row < 4; row++ {
+			for col := 0; col < 2; col++ {
+				if (char-brailleBase)&brailleDots[row][col] != 0 {
+					p.fillRect(fg, left+float64(col)*dotWidth+(dotWidth-size)/2, top+float64(row)*dotHeight+(dotHeight-size)/2, size, size)
+				}
+			}
+		}
+		return
+	}
+
+	p.drawText(char, fg, left+float64(m.paddingLeft), top+float64(m.paddingTop))
+}
+
+func quadrantMask(char rune) int {
+	for mask, quadrant := range quadrantChars {
+		if quadrant == char {
+			return mask
+		}
+	}
+	return 0
+}
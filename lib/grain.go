@@ -0,0 +1,31 @@
+package lib
+
+import (
+	"image"
+	"math/rand/v2"
+)
+
+const grainStream = 0x9e3779b97f4a7c15
+
+func applyGrain(img image.Image, columns, rows int, amount float64, seed int64) *image.NRGBA {
+	rng := rand.New(rand.NewPCG(uint64(seed), grainStream))
+	noise := make([]float64, columns*rows)
+	for i := range noise {
+		noise[i] = (rng.Float64()*2 - 1) * amount * 255
+	}
+
+	dst := cloneNRGBA(img)
+	width, height := dst.Rect.Dx(), dst.Rect.Dy()
+	for y := 0; y < height; y++ {
+		row := dst.Pix[y*dst.Stride:]
+		cellRow := min(y*rows/height, rows-1) * columns
+		for x := 0; x < width; x++ {
+			n := noise[cellRow+min(x*columns/width, columns-1)]
+			p := row[x*4 : x*4+3]
+			for c := range p {
+				p[c] = clampUint8(float64(p[c]) + n)
+			}
+		}
+	}
+	return dst
+}
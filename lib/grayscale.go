@@ -0,0 +1,28 @@
+package lib
+
+import "math"
+
+const (
+	LuminanceAverage    = "average"
+	LuminanceLuminosity = "luminosity"
+	LuminanceLightness  = "lightness"
+)
+
+func LuminanceFormulaNames() []string {
+	return []string{LuminanceAverage, LuminanceLuminosity, LuminanceLightness}
+}
+
+func intensityOf(pix []uint8, formula string) float64 {
+	r, g, b := float64(pix[0]), float64(pix[1]), float64(pix[2])
+
+	var value float64
+	switch formula {
+	case LuminanceLuminosity:
+		value = 0.2126*r + 0.7152*g + 0.0722*b
+	case LuminanceLightness:
+		value = (math.Max(r, math.Max(g, b)) + math.Min(r, math.Min(g, b))) / 2
+	default:
+		value = (r + g + b) / 3
+	}
+	return value / 255 * float64(pix[3]) / 255
+}
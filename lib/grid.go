@@ -0,0 +1,92 @@
+package lib
+
+import (
+	"fmt"
+	"image/color"
+
+	"github.com/leaanthony/go-ansi-parser"
+)
+
+const maxStyledElements = 100_000
+
+type Cell struct {
+	Char rune
+	FG   color.RGBA
+	BG   color.RGBA
+}
+
+type Grid [][]Cell
+
+func newGrid(width, height int) Grid {
+	cells := make([]Cell, width*height)
+	grid := make(Grid, height)
+	for y := range grid {
+		grid[y] = cells[y*width : (y+1)*width]
+	}
+	return grid
+}
+
+func rgbColor(r, g, b uint8) color.RGBA {
+	return color.RGBA{R: r, G: g, B: b, A: 0xFF}
+}
+
+func colToRGBA(col *ansi.Col) color.RGBA {
+	if col == nil {
+		return color.RGBA{}
+	}
+	return rgbColor(col.Rgb.R, col.Rgb.G, col.Rgb.B)
+}
+
+func gridToStyledText(grid Grid, limit int) ([]*ansi.StyledText, error) {
+	cols := make(map[color.RGBA]*ansi.Col)
+	colOf := func(c color.RGBA) *ansi.Col {
+		if c.A == 0 {
+			return nil
+		}
+		if col, ok := cols[c]; ok {
+			return col
+		}
+		col := &ansi.Col{Id: 256, Hex: fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B), Rgb: ansi.Rgb{R: c.R, G: c.G, B: c.B}}
+		cols[c] = col
+		return col
+	}
+
+	var styledText []*ansi.StyledText
+	var current *ansi.StyledText
+	var label []rune
+	flush := func() {
+		if current != nil {
+			current.Label = string(label)
+			styledText = append(styledText, current)
+			current, label = nil, label[:0]
+		}
+	}
+	for _, row := range grid {
+		for _, cell := range row {
+			fg, bg := colOf(cell.FG), colOf(cell.BG)
+			if current == nil || current.FgCol != fg || current.BgCol != bg {
+				flush()
+				current = &ansi.StyledText{FgCol: fg, BgCol: bg}
+				if fg != nil || bg != nil {
+					current.ColourMode = ansi.TrueColour
+				}
+			}
+			label = append(label, cell.Char)
+		}
+		flush()
+		styledText = append(styledText, &ansi.StyledText{Label: "\n"})
+	}
+	return styledText, checkStyledElements(styledText, limit)
+}
+
+func styledElementLimit(l Limits) int {
+	scale := float64(l.MaxASCIIDimension) / DefaultMaxASCIIDimension
+	return max(maxStyledElements, int(maxStyledElements*scale*scale))
+}
+
+func checkStyledElements(styledText []*ansi.StyledText, limit int) error {
+	if len(styledText) > limit {
+		return newLimitError(len(styledText), limit, "too many styled text elements: %d (max: %d)", len(styledText), limit)
+	}
+	return nil
+}
@@ -0,0 +1,28 @@
+package lib
+
+import (
+	"image"
+
+	"github.com/disintegration/imaging"
+)
+
+const upperHalfBlock = '▀'
+
+func renderHalfBlocks(img image.Image, width, height int, opts Options) Grid {
+	resized := imaging.Resize(img, width, height*2, resampleFilter(opts.Resample))
+
+	grid := newGrid(width, height)
+	for row := 0; row < height; row++ {
+		top := resized.Pix[(row*2)*resized.Stride:]
+		bottom := resized.Pix[(row*2+1)*resized.Stride:]
+		for col := 0; col < width; col++ {
+			i := col * 4
+			grid[row][col] = Cell{
+				Char: upperHalfBlock,
+				FG:   rgbColor(top[i], top[i+1], top[i+2]),
+				BG:   rgbColor(bottom[i], bottom[i+1], bottom[i+2]),
+			}
+		}
+	}
+	return grid
+}
@@ -0,0 +1,58 @@
+package lib
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/ajstarks/svgo"
+	"github.com/leaanthony/go-ansi-parser"
+)
+
+const ModeHalftone = "halftone"
+
+func halftoneLevels(opts Options) map[rune]float64 {
+	if opts.Mode != ModeHalftone {
+		return nil
+	}
+
+	ramp := []rune(charsetPresets[opts.Charset])
+	levels := make(map[rune]float64, len(ramp))
+	for i, char := range ramp {
+		levels[char] = float64(i) / float64(max(len(ramp)-1, 1))
+	}
+	return levels
+}
+
+func dotRadius(level float64, m cellMetrics) float64 {
+	return float64(min(m.charWidth, m.lineHeight)) / 2 * math.Sqrt(level)
+}
+
+func renderDots(canvas *svg.SVG, line []*ansi.StyledText, yPos int, m cellMetrics, classes *colorClasses) {
+	centerY := float64(yPos-m.paddingTop) + float64(m.lineHeight)/2
+	col := 0
+	for _, styledChar := range line {
+		for _, char := range styledChar.Label {
+			if level, ok := m.dots[char]; ok && level > 0 && !styledChar.Invisible() {
+				fmt.Fprintf(canvas.Writer, `<circle cx="%g" cy="%g" r="%.2f" class="%s"/>`+"\n",
+					float64(col*m.charWidth)+float64(runeWidth(char)*m.charWidth)/2, centerY, dotRadius(level, m), classes.textClass(styledChar))
+			}
+			col += runeWidth(char)
+		}
+	}
+}
+
+func withoutDots(line []*ansi.StyledText, dots map[rune]float64) []*ansi.StyledText {
+	text := make([]*ansi.StyledText, len(line))
+	for i, styledChar := range line {
+		stripped := *styledChar
+		stripped.Label = strings.Map(func(char rune) rune {
+			if _, ok := dots[char]; ok {
+				return ' '
+			}
+			return char
+		}, styledChar.Label)
+		text[i] = &stripped
+	}
+	return text
+}
@@ -0,0 +1,50 @@
+package lib
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/leaanthony/go-ansi-parser"
+)
+
+const kittyChunkSize = 4096
+
+func renderToInlineImage(styledText []*ansi.StyledText, opts Options) (string, error) {
+	var sb strings.Builder
+	if err := writeInlineImage(&sb, styledText, opts); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+func writeInlineImage(w io.Writer, styledText []*ansi.StyledText, opts Options) error {
+	data, err := renderToPNG(styledText, opts)
+	if err != nil {
+		return err
+	}
+	encoded := base64.StdEncoding.EncodeToString(data)
+
+	if opts.OutputFormat == OutputITerm2 {
+		_, err = fmt.Fprintf(w, "\x1b]1337;File=inline=1;size=%d;preserveAspectRatio=1:%s\a\n", len(data), encoded)
+		return err
+	}
+
+	for i := 0; i < len(encoded); i += kittyChunkSize {
+		end := min(i+kittyChunkSize, len(encoded))
+		more := 0
+		if end < len(encoded) {
+			more = 1
+		}
+		control := fmt.Sprintf("m=%d", more)
+		if i == 0 {
+			control = "a=T,f=100," + control
+		}
+		if _, err := fmt.Fprintf(w, "\x1b_G%s;%s\x1b\\", control, encoded[i:end]); err != nil {
+			return err
+		}
+	}
+	_, err = io.WriteString(w, "\n")
+	return err
+}
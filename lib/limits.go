@@ -0,0 +1,112 @@
+package lib
+
+import (
+	"math"
+	"sync"
+)
+
+const (
+	DefaultMaxImageSize      = 50 * 1024 * 1024
+	DefaultMaxImagePixels    = 50_000_000
+	DefaultMaxOutputSize     = 10 * 1024 * 1024
+	DefaultMaxASCIIChars     = 5000000
+	DefaultMaxASCIIDimension = 500
+	DefaultMaxMemory         = 512 * 1024 * 1024
+
+	MaxASCIIDimensionCeiling = 4000
+)
+
+type Limits struct {
+	MaxImageSize      int
+	MaxImagePixels    int
+	MaxOutputSize     int
+	MaxASCIIChars     int
+	MaxASCIIDimension int
+	MaxMemory         int
+}
+
+var (
+	limitsMu sync.RWMutex
+	limits   = DefaultLimits()
+)
+
+var (
+	minLimits = Limits{
+		MaxImageSize:      1024 * 1024,
+		MaxImagePixels:    1_000_000,
+		MaxOutputSize:     1024 * 1024,
+		MaxASCIIChars:     10_000,
+		MaxASCIIDimension: 10,
+		MaxMemory:         16 * 1024 * 1024,
+	}
+	maxLimits = Limits{
+		MaxImageSize:      512 * 1024 * 1024,
+		MaxImagePixels:    500_000_000,
+		MaxOutputSize:     256 * 1024 * 1024,
+		MaxASCIIChars:     50_000_000,
+		MaxASCIIDimension: MaxASCIIDimensionCeiling,
+		MaxMemory:         math.MaxInt32,
+	}
+)
+
+func DefaultLimits() Limits {
+	return Limits{
+		MaxImageSize:      DefaultMaxImageSize,
+		MaxImagePixels:    DefaultMaxImagePixels,
+		MaxOutputSize:     DefaultMaxOutputSize,
+		MaxASCIIChars:     DefaultMaxASCIIChars,
+		MaxASCIIDimension: DefaultMaxASCIIDimension,
+		MaxMemory:         DefaultMaxMemory,
+	}
+}
+
+func CurrentLimits() Limits {
+	limitsMu.RLock()
+	defer limitsMu.RUnlock()
+	return limits
+}
+
+func (o Options) limits() Limits {
+	if o.frameLimits != nil {
+		return *o.frameLimits
+	}
+	l := CurrentLimits()
+	if o.MaxASCIIDimension > 0 {
+		l.MaxASCIIDimension = o.MaxASCIIDimension
+	}
+	return l
+}
+
+func ConfigureLimits(l Limits) (Limits, error) {
+	limitsMu.Lock()
+	defer limitsMu.Unlock()
+
+	updated := limits
+	fields := []struct {
+		name   string
+		value  int
+		target *int
+		min    int
+		max    int
+	}{
+		{"maxImageSize", l.MaxImageSize, &updated.MaxImageSize, minLimits.MaxImageSize, maxLimits.MaxImageSize},
+		{"maxImagePixels", l.MaxImagePixels, &updated.MaxImagePixels, minLimits.MaxImagePixels, maxLimits.MaxImagePixels},
+		{"maxOutputSize", l.MaxOutputSize, &updated.MaxOutputSize, minLimits.MaxOutputSize, maxLimits.MaxOutputSize},
+		{"maxASCIIChars", l.MaxASCIIChars, &updated.MaxASCIIChars, minLimits.MaxASCIIChars, maxLimits.MaxASCIIChars},
+		{"maxASCIIDimension", l.MaxASCIIDimension, &updated.MaxASCIIDimension, minLimits.MaxASCIIDimension, maxLimits.MaxASCIIDimension},
+		{"maxMemory", l.MaxMemory, &updated.MaxMemory, minLimits.MaxMemory, maxLimits.MaxMemory},
+	}
+	for _, f := range fields {
+		if f.value == 0 {
+			continue
+		}
+		if f.value < f.min || f.value > f.max {
+			return limits, NewOptionError(f.name, "%s must be between %s and %s, got %s",
+				f.name, formatNumber(f.min), formatNumber(f.max), formatNumber(f.value))
+		}
+		*f.target = f.value
+	}
+
+	limits = updated
+	return limits, nil
+}
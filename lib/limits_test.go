@@ -0,0 +1,77 @@
+package lib
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestOptionsLimits(t *testing.T) {
+	frame := Limits{MaxASCIIDimension: 42, MaxASCIIChars: 1000}
+	tests := []struct {
+		name          string
+		maxDimension  int
+		frameLimits   *Limits
+		wantDimension int
+	}{
+		{"configured limit", 0, nil, 0},
+		{"lower override", 100, nil, 100},
+		{"higher override", 2000, nil, 2000},
+		{"frame limits win", 2000, &frame, 42},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := DefaultOptions()
+			opts.MaxASCIIDimension = tt.maxDimension
+			opts.frameLimits = tt.frameLimits
+			got := opts.limits()
+			want := tt.wantDimension
+			if want == 0 {
+				want = CurrentLimits().MaxASCIIDimension
+			}
+			if got.MaxASCIIDimension != want {
+				t.Errorf("MaxASCIIDimension = %d, want %d", got.MaxASCIIDimension, want)
+			}
+			if tt.frameLimits == nil && got.MaxMemory != CurrentLimits().MaxMemory {
+				t.Errorf("MaxMemory = %d, want the configured %d", got.MaxMemory, CurrentLimits().MaxMemory)
+			}
+		})
+	}
+}
+
+func TestConfigureLimits(t *testing.T) {
+	saved := CurrentLimits()
+	t.Cleanup(func() { ConfigureLimits(saved) })
+
+	tests := []struct {
+		name       string
+		limits     Limits
+		wantOption string
+	}{
+		{"zero keeps current", Limits{}, ""},
+		{"valid dimension", Limits{MaxASCIIDimension: 1000}, ""},
+		{"dimension above ceiling", Limits{MaxASCIIDimension: MaxASCIIDimensionCeiling + 1}, "maxASCIIDimension"},
+		{"memory below minimum", Limits{MaxMemory: 1}, "maxMemory"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := CurrentLimits()
+			got, err := ConfigureLimits(tt.limits)
+			if tt.wantOption == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				if tt.limits.MaxASCIIDimension != 0 && got.MaxASCIIDimension != tt.limits.MaxASCIIDimension {
+					t.Errorf("MaxASCIIDimension = %d, want %d", got.MaxASCIIDimension, tt.limits.MaxASCIIDimension)
+				}
+				return
+			}
+			var libErr *Error
+			if !errors.As(err, &libErr) || libErr.Code != CodeBadOption || libErr.Option != tt.wantOption {
+				t.Fatalf("err = %v, want %s for %q", err, CodeBadOption, tt.wantOption)
+			}
+			if CurrentLimits() != before {
+				t.Errorf("limits changed after a rejected update")
+			}
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package lib
+
+import "math"
+
+func srgbToLinear(v float64) float64 {
+	if v <= 0.04045 {
+		return v / 12.92
+	}
+	return math.Pow((v+0.055)/1.055, 2.4)
+}
+
+func linearToSRGB(v float64) float64 {
+	if v <= 0.0031308 {
+		return v * 12.92
+	}
+	return 1.055*math.Pow(v, 1/2.4) - 0.055
+}
+
+func blendChannel(fg, bg, alpha float64, linear bool) float64 {
+	if alpha <= 0 {
+		return bg
+	}
+	if linear {
+		fg, bg = srgbToLinear(fg), srgbToLinear(bg)
+	}
+	v := fg*alpha + bg*(1-alpha)
+	if linear {
+		v = linearToSRGB(v)
+	}
+	return clampFloat(v, 0, 1)
+}
@@ -0,0 +1,56 @@
+package lib
+
+import "io"
+
+var lineFormats = []string{OutputSVG, OutputANSI, OutputText, OutputIRC}
+
+const (
+	LinePrologue = -1
+	LineEpilogue = -2
+)
+
+type LineFunc func(row int, data []byte) error
+
+type lineWriter struct {
+	w      io.Writer
+	onLine LineFunc
+	buf    []byte
+	row    int
+}
+
+func newLineWriter(w io.Writer, onLine LineFunc) *lineWriter {
+	return &lineWriter{w: w, onLine: onLine}
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+	return w.w.Write(p)
+}
+
+func (w *lineWriter) emit(row int) error {
+	err := w.onLine(row, w.buf)
+	w.buf = w.buf[:0]
+	if err != nil {
+		return NewError(CodeRender, "line callback failed: %w", err)
+	}
+	return nil
+}
+
+func (w *lineWriter) begin() error {
+	if len(w.buf) == 0 {
+		return nil
+	}
+	return w.emit(LinePrologue)
+}
+
+func (w *lineWriter) endLine() error {
+	w.row++
+	return w.emit(w.row - 1)
+}
+
+func (w *lineWriter) finish() error {
+	if len(w.buf) == 0 {
+		return nil
+	}
+	return w.emit(LineEpilogue)
+}
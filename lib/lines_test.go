@@ -0,0 +1,46 @@
+package lib
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestOnLineRows(t *testing.T) {
+	for _, format := range lineFormats {
+		t.Run(format, func(t *testing.T) {
+			var rows []int
+			var joined strings.Builder
+			opts := DefaultOptions()
+			opts.OutputFormat = format
+			opts.TargetWidth = 12
+			opts.OnLine = func(row int, data []byte) error {
+				rows = append(rows, row)
+				joined.Write(data)
+				return nil
+			}
+
+			result, err := ProcessImage(readFixture(t, "video-001.png"), opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if output := result.SVG + result.Text; joined.String() != output {
+				t.Errorf("joined lines differ from the result output")
+			}
+
+			if format == OutputSVG {
+				if rows[0] != LinePrologue || rows[len(rows)-1] != LineEpilogue {
+					t.Fatalf("rows = %v, want a prologue first and an epilogue last", rows)
+				}
+				rows = rows[1 : len(rows)-1]
+			}
+			if len(rows) != result.ASCIIHeight {
+				t.Fatalf("got %d row callbacks, want %d", len(rows), result.ASCIIHeight)
+			}
+			for i, row := range rows {
+				if row != i {
+					t.Fatalf("rows = %v, want 0 to %d", rows, result.ASCIIHeight-1)
+				}
+			}
+		})
+	}
+}
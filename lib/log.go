@@ -0,0 +1,75 @@
+package lib
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync/atomic"
+)
+
+type LogLevel int32
+
+const (
+	LevelSilent LogLevel = iota
+	LevelError
+	LevelWarn
+	LevelInfo
+	LevelDebug
+)
+
+var levelNames = []string{"silent", "error", "warn", "info", "debug"}
+
+type LogFunc func(level LogLevel, message string)
+
+var (
+	logLevel atomic.Int32
+	logger   atomic.Pointer[LogFunc]
+)
+
+func init() {
+	logLevel.Store(int32(LevelInfo))
+	SetLogger(nil)
+}
+
+func (l LogLevel) String() string {
+	if l < LevelSilent || int(l) >= len(levelNames) {
+		return fmt.Sprintf("LogLevel(%d)", int(l))
+	}
+	return levelNames[l]
+}
+
+func ParseLogLevel(name string) (LogLevel, error) {
+	for i, levelName := range levelNames {
+		if strings.EqualFold(name, levelName) {
+			return LogLevel(i), nil
+		}
+	}
+	return LevelSilent, NewOptionError("logLevel", "unknown log level %q (valid levels: %s)", name, strings.Join(levelNames, ", "))
+}
+
+func SetLogLevel(level LogLevel) {
+	logLevel.Store(int32(level))
+}
+
+func CurrentLogLevel() LogLevel {
+	return LogLevel(logLevel.Load())
+}
+
+func SetLogger(fn LogFunc) {
+	if fn == nil {
+		fn = stderrLogger
+	}
+	logger.Store(&fn)
+}
+
+func stderrLogger(level LogLevel, message string) {
+	fmt.Fprintf(os.Stderr, "%s: %s\n", level, message)
+}
+
+func Logf(level LogLevel, format string, args ...any) {
+	if level == LevelSilent || level > CurrentLogLevel() {
+		return
+	}
+
+	(*logger.Load())(level, fmt.Sprintf(format, args...))
+}
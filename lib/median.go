@@ -0,0 +1,62 @@
+package lib
+
+import (
+	"image"
+)
+
+func medianFilter(img image.Image, radius int) image.Image {
+	src := cloneNRGBA(img)
+	defer releaseNRGBA(src)
+	bounds := src.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	dst := image.NewNRGBA(image.Rect(0, 0, width, height))
+
+	for y := 0; y < height; y++ {
+		y0, y1 := max(0, y-radius), min(height-1, y+radius)
+		var histograms [3][256]int
+		count := 0
+
+		addColumn := func(x, delta int) {
+			for yy := y0; yy <= y1; yy++ {
+				i := yy*src.Stride + x*4
+				for c := 0; c < 3; c++ {
+					histograms[c][src.Pix[i+c]] += delta
+				}
+				count += delta
+			}
+		}
+
+		for x := 0; x <= min(width-1, radius); x++ {
+			addColumn(x, 1)
+		}
+		for x := 0; x < width; x++ {
+			if x > 0 {
+				if out := x - radius - 1; out >= 0 {
+					addColumn(out, -1)
+				}
+				if in := x + radius; in < width {
+					addColumn(in, 1)
+				}
+			}
+
+			i := y*dst.Stride + x*4
+			for c := 0; c < 3; c++ {
+				dst.Pix[i+c] = histogramMedian(&histograms[c], count)
+			}
+			dst.Pix[i+3] = src.Pix[y*src.Stride+x*4+3]
+		}
+	}
+	return dst
+}
+
+func histogramMedian(histogram *[256]int, count int) uint8 {
+	half := (count + 1) / 2
+	sum := 0
+	for v, n := range histogram {
+		sum += n
+		if sum >= half {
+			return uint8(v)
+		}
+	}
+	return 255
+}
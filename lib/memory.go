@@ -0,0 +1,59 @@
+package lib
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+)
+
+const (
+	intermediateImageCopies = 4
+	estimatedBytesPerCell   = 160
+)
+
+func bytesPerPixel(model color.Model) int {
+	switch model {
+	case color.RGBA64Model, color.NRGBA64Model, color.Gray16Model:
+		return 8
+	}
+	return 4
+}
+
+func estimateMemory(width, height, pixelBytes int, decoded bool, opts Options) int {
+	total := 0
+	if decoded {
+		total += width * height * pixelBytes
+	}
+
+	scaledWidth, scaledHeight := width, height
+	if dim := opts.MaxProcessDimension; dim > 0 && max(width, height) > dim {
+		scale := float64(dim) / float64(max(width, height))
+		scaledWidth, scaledHeight = max(int(float64(width)*scale), 1), max(int(float64(height)*scale), 1)
+	}
+	total += scaledWidth * scaledHeight * pixelBytes * intermediateImageCopies
+
+	maxDim := opts.limits().MaxASCIIDimension
+	cols := min(opts.TargetWidth, maxDim)
+	rows := min(max(int(float64(cols)*float64(scaledHeight)/float64(scaledWidth)), 1), maxDim)
+	if opts.TargetHeight > 0 {
+		rows = min(rows, opts.TargetHeight)
+	}
+	return total + cols*rows*estimatedBytesPerCell
+}
+
+func checkMemoryBudget(estimate int) error {
+	budget := CurrentLimits().MaxMemory
+	if estimate > budget {
+		return newLimitError(estimate, budget, "estimated memory use of %s bytes exceeds the memory budget of %s bytes",
+			formatNumber(estimate), formatNumber(budget))
+	}
+	return nil
+}
+
+func checkImageMemory(imageData []byte, opts Options) error {
+	config, _, err := image.DecodeConfig(bytes.NewReader(imageData))
+	if err != nil {
+		return NewError(CodeDecode, "failed to decode image: %w", err)
+	}
+	return checkMemoryBudget(estimateMemory(config.Width, config.Height, bytesPerPixel(config.ColorModel), true, opts))
+}
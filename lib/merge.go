@@ -0,0 +1,89 @@
+package lib
+
+import (
+	"image/color"
+	"math"
+)
+
+const maxColorMergeTolerance = 100
+
+type labColor [3]float64
+
+func rgbToLab(c color.RGBA) labColor {
+	r, g, b := srgbToLinear(float64(c.R)/255), srgbToLinear(float64(c.G)/255), srgbToLinear(float64(c.B)/255)
+	x := (0.4124*r + 0.3576*g + 0.1805*b) / 0.95047
+	y := 0.2126*r + 0.7152*g + 0.0722*b
+	z := (0.0193*r + 0.1192*g + 0.9505*b) / 1.08883
+
+	f := func(t float64) float64 {
+		if t > 216.0/24389 {
+			return math.Cbrt(t)
+		}
+		return (24389.0/27*t + 16) / 116
+	}
+	fx, fy, fz := f(x), f(y), f(z)
+	return labColor{116*fy - 16, 500 * (fx - fy), 200 * (fy - fz)}
+}
+
+func deltaE(a, b color.RGBA) float64 {
+	la, lb := rgbToLab(a), rgbToLab(b)
+	return math.Sqrt((la[0]-lb[0])*(la[0]-lb[0]) + (la[1]-lb[1])*(la[1]-lb[1]) + (la[2]-lb[2])*(la[2]-lb[2]))
+}
+
+type colorRun struct {
+	sum         [3]int
+	count       int
+	transparent bool
+}
+
+func (r *colorRun) add(c color.RGBA) {
+	if c.A == 0 {
+		r.transparent = true
+		return
+	}
+	r.sum[0] += int(c.R)
+	r.sum[1] += int(c.G)
+	r.sum[2] += int(c.B)
+	r.count++
+}
+
+func (r *colorRun) average() color.RGBA {
+	return rgbColor(uint8(r.sum[0]/r.count), uint8(r.sum[1]/r.count), uint8(r.sum[2]/r.count))
+}
+
+func (r *colorRun) accepts(c color.RGBA, tolerance float64) bool {
+	if c.A == 0 {
+		return r.count == 0
+	}
+	return !r.transparent && (r.count == 0 || deltaE(r.average(), c) <= tolerance)
+}
+
+func mergeColorRuns(grid Grid, tolerance float64) {
+	for _, row := range grid {
+		start := 0
+		var fg, bg colorRun
+		flush := func(end int) {
+			for x := start; x < end; x++ {
+				if fg.count > 0 {
+					row[x].FG = fg.average()
+				}
+				if bg.count > 0 {
+					row[x].BG = bg.average()
+				}
+			}
+			start, fg, bg = end, colorRun{}, colorRun{}
+		}
+
+		for x, cell := range row {
+			glyph := cell.Char != ' '
+			if (glyph && !fg.accepts(cell.FG, tolerance)) || !bg.accepts(cell.BG, tolerance) {
+				flush(x)
+			}
+			if glyph {
+				fg.add(cell.FG)
+			}
+			bg.add(cell.BG)
+		}
+		flush(len(row))
+	}
+}
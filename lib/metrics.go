@@ -0,0 +1,133 @@
+package lib
+
+import (
+	"math"
+
+	"github.com/leaanthony/go-ansi-parser"
+)
+
+const (
+	DefaultCharWidth     = 16
+	DefaultLineHeight    = 16
+	DefaultFontSize      = 16
+	DefaultPaddingTop    = -2
+	DefaultPaddingBottom = 2
+	DefaultPaddingLeft   = 1
+	DefaultPaddingRight  = -6
+
+	maxCellSize = 256
+	maxPadding  = 256
+	minSpacing  = 0.5
+	maxSpacing  = 4.0
+)
+
+type cellMetrics struct {
+	charWidth     int
+	lineHeight    int
+	glyphWidth    int
+	fontSize      int
+	paddingTop    int
+	paddingBottom int
+	paddingLeft   int
+	paddingRight  int
+	dots          map[rune]float64
+}
+
+func (o Options) cellMetrics() cellMetrics {
+	return cellMetrics{
+		charWidth:     spaced(o.CharWidth, o.LetterSpacing),
+		lineHeight:    spaced(o.LineHeight, o.LineSpacing),
+		glyphWidth:    o.CharWidth,
+		fontSize:      o.FontSize,
+		paddingTop:    o.PaddingTop,
+		paddingBottom: o.PaddingBottom,
+		paddingLeft:   o.PaddingLeft,
+		paddingRight:  o.PaddingRight,
+		dots:          halftoneLevels(o),
+	}
+}
+
+func (o Options) columnsForWidth(pixels int) int {
+	m := o.cellMetrics()
+	columns := (pixels - m.paddingLeft - m.paddingRight - 2*borderInset(o)) / m.charWidth
+	if o.Border == BorderASCII {
+		columns -= 2 * (1 + o.BorderPadding)
+	}
+	return max(columns/o.glyphColumns(), 1)
+}
+
+func (o *Options) checkOutputWidth(styledText []*ansi.StyledText) {
+	if o.OutputWidth <= 0 {
+		return
+	}
+	width, _ := calculateSVGDimensions(splitStyledTextByLine(styledText), o.cellMetrics())
+	if width += 2 * borderInset(*o); width > o.OutputWidth {
+		o.warn(WarnOutputWidth, "outputWidth", "rendered width of %dpx exceeds the requested output width of %dpx", width, o.OutputWidth)
+	}
+}
+
+func (o Options) glyphColumns() int {
+	columns := 1
+	if o.Mode == ModeEmoji {
+		for _, emoji := range emojiColors {
+			columns = max(columns, runeWidth(emoji.char))
+		}
+		return columns
+	}
+	for _, char := range charsetPresets[o.Charset] {
+		columns = max(columns, runeWidth(char))
+	}
+	return columns
+}
+
+func spaced(size int, spacing float64) int {
+	if spacing == 0 {
+		return size
+	}
+	return max(1, int(math.Round(float64(size)*spacing)))
+}
+
+func validateCellMetrics(opts Options) error {
+	sizes := []struct {
+		name  string
+		value int
+	}{
+		{"charWidth", opts.CharWidth},
+		{"lineHeight", opts.LineHeight},
+		{"fontSize", opts.FontSize},
+	}
+	for _, size := range sizes {
+		if size.value < 0 || size.value > maxCellSize {
+			return NewOptionError(size.name, "%s must be between 0 and %d, got %d", size.name, maxCellSize, size.value)
+		}
+	}
+
+	spacings := []struct {
+		name  string
+		value float64
+	}{
+		{"letterSpacing", opts.LetterSpacing},
+		{"lineSpacing", opts.LineSpacing},
+	}
+	for _, spacing := range spacings {
+		if spacing.value != 0 && (spacing.value < minSpacing || spacing.value > maxSpacing) {
+			return NewOptionError(spacing.name, "%s must be between %.1f and %.0f, got %.2f", spacing.name, minSpacing, maxSpacing, spacing.value)
+		}
+	}
+
+	paddings := []struct {
+		name  string
+		value int
+	}{
+		{"paddingTop", opts.PaddingTop},
+		{"paddingBottom", opts.PaddingBottom},
+		{"paddingLeft", opts.PaddingLeft},
+		{"paddingRight", opts.PaddingRight},
+	}
+	for _, padding := range paddings {
+		if padding.value < -maxPadding || padding.value > maxPadding {
+			return NewOptionError(padding.name, "%s must be between %d and %d, got %d", padding.name, -maxPadding, maxPadding, padding.value)
+		}
+	}
+	return nil
+}
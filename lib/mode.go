@@ -0,0 +1,120 @@
+package lib
+
+import (
+	"cmp"
+	"image"
+	"slices"
+	"sort"
+	"sync"
+
+	"github.com/disintegration/imaging"
+)
+
+const (
+	ModeASCII     = "ascii"
+	ModeBraille   = "braille"
+	ModeHalfBlock = "halfblock"
+	ModeQuadrant  = "quadrant"
+)
+
+var builtinModes = []string{ModeASCII, ModeBraille, ModeHalfBlock, ModeQuadrant, ModeThreshold, ModeEmoji, ModeHalftone, ModeMosaic}
+
+type Converter interface {
+	Convert(img image.Image, width, height int, opts Options) (Grid, error)
+}
+
+type ConverterFunc func(img image.Image, width, height int, opts Options) (Grid, error)
+
+func (f ConverterFunc) Convert(img image.Image, width, height int, opts Options) (Grid, error) {
+	return f(img, width, height, opts)
+}
+
+var (
+	convertersMu sync.RWMutex
+	converters   = map[string]Converter{
+		ModeASCII:     gridConverter(renderASCII),
+		ModeBraille:   gridConverter(renderBraille),
+		ModeHalfBlock: gridConverter(grayscaleFirst(renderHalfBlocks)),
+		ModeQuadrant:  gridConverter(grayscaleFirst(renderQuadrants)),
+		ModeThreshold: gridConverter(renderThreshold),
+		ModeEmoji:     gridConverter(renderEmoji),
+		ModeHalftone:  gridConverter(renderCharset),
+		ModeMosaic:    gridConverter(grayscaleFirst(renderMosaic)),
+	}
+)
+
+func RegisterConverter(mode string, converter Converter) error {
+	if mode == "" || converter == nil {
+		return NewError(CodeBadInput, "converter mode and implementation are required")
+	}
+	if slices.Contains(builtinModes, mode) {
+		return NewOptionError("mode", "cannot replace built-in mode %q", mode)
+	}
+
+	convertersMu.Lock()
+	defer convertersMu.Unlock()
+	converters[mode] = converter
+	return nil
+}
+
+func ModeNames() []string {
+	convertersMu.RLock()
+	defer convertersMu.RUnlock()
+
+	var custom []string
+	for mode := range converters {
+		if !slices.Contains(builtinModes, mode) {
+			custom = append(custom, mode)
+		}
+	}
+	sort.Strings(custom)
+	return append(slices.Clone(builtinModes), custom...)
+}
+
+func (o Options) resolveConverter() (Converter, error) {
+	if o.converter != nil {
+		return o.converter, nil
+	}
+	converter, ok := converterFor(o.Mode)
+	if !ok {
+		return nil, NewOptionError("mode", "unknown mode %q", o.Mode)
+	}
+	return converter, nil
+}
+
+func converterFor(mode string) (Converter, bool) {
+	convertersMu.RLock()
+	defer convertersMu.RUnlock()
+	converter, ok := converters[cmp.Or(mode, ModeASCII)]
+	return converter, ok
+}
+
+func gridConverter(render func(img image.Image, width, height int, opts Options) Grid) Converter {
+	return ConverterFunc(func(img image.Image, width, height int, opts Options) (Grid, error) {
+		return render(img, width, height, opts), nil
+	})
+}
+
+func grayscaleFirst(render func(img image.Image, width, height int, opts Options) Grid) func(img image.Image, width, height int, opts Options) Grid {
+	return func(img image.Image, width, height int, opts Options) Grid {
+		if opts.Grayscale {
+			img = imaging.Grayscale(img)
+		}
+		return render(img, width, height, opts)
+	}
+}
+
+func renderASCII(img image.Image, width, height int, opts Options) Grid {
+	grid := renderCharset(img, width, height, opts)
+	if opts.Charset == defaultCharset && opts.Dither == DitherNone && !opts.Grayscale &&
+		opts.LuminanceFormula == LuminanceAverage && opts.Resample == ResampleLanczos {
+		palette := terminalPalette(PaletteANSI256)
+		for _, row := range grid {
+			for x := range row {
+				c := nearestPaletteColor(palette, [3]uint8{row[x].FG.R, row[x].FG.G, row[x].FG.B})
+				row[x].FG = rgbColor(c[0], c[1], c[2])
+			}
+		}
+	}
+	return grid
+}
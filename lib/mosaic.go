@@ -0,0 +1,25 @@
+package lib
+
+import (
+	"image"
+
+	"github.com/disintegration/imaging"
+)
+
+const ModeMosaic = "mosaic"
+
+func renderMosaic(img image.Image, width, height int, opts Options) Grid {
+	resized := imaging.Resize(img, width, height, resampleFilter(opts.Resample))
+
+	grid := newGrid(width, height)
+	for y := 0; y < height; y++ {
+		row := resized.Pix[y*resized.Stride:]
+		for x := 0; x < width; x++ {
+			grid[y][x] = Cell{
+				Char: ' ',
+				BG:   rgbColor(row[x*4], row[x*4+1], row[x*4+2]),
+			}
+		}
+	}
+	return grid
+}
@@ -0,0 +1,460 @@
+package lib
+
+import (
+	"cmp"
+	"math"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	DefaultMaxProcessDimension = 1024
+	defaultUnsharpRadius       = 1.0
+)
+
+type Options struct {
+	TargetWidth             int
+	TargetHeight            int
+	OutputWidth             int
+	Fit                     string
+	Preset                  string
+	Theme                   string
+	MaxProcessDimension     int
+	MaxASCIIDimension       int
+	Resample                string
+	LinearLight             bool
+	FontFamily              string
+	EmbedFont               []byte
+	Title                   string
+	Description             string
+	Author                  string
+	Sauce                   bool
+	DefaultTextColor        string
+	Border                  string
+	BorderColor             string
+	BorderWidth             int
+	BorderPadding           int
+	TrimWhitespace          bool
+	DiffThreshold           float64
+	DiffColor               string
+	Caption                 string
+	CaptionPosition         string
+	CaptionColor            string
+	CaptionOpacity          float64
+	OutlineColor            string
+	OutlineWidth            float64
+	ShadowColor             string
+	ShadowOffset            float64
+	ShadowBlur              float64
+	OutputFormat            string
+	RasterScale             float64
+	FrameDelay              int
+	BackgroundGradient      string
+	BackgroundGradientAngle float64
+	CharWidth               int
+	LineHeight              int
+	FontSize                int
+	LetterSpacing           float64
+	LineSpacing             float64
+	PaddingTop              int
+	PaddingBottom           int
+	PaddingLeft             int
+	PaddingRight            int
+	Brightness              float64
+	Contrast                float64
+	Sharpen                 float64
+	BackgroundColor         string
+	TransparencyColor       string
+	TransparencyThreshold   float64
+	ChromaKey               string
+	ChromaKeyTolerance      float64
+	Charset                 string
+	Mode                    string
+	Dither                  string
+	Threshold               float64
+	Grayscale               bool
+	LuminanceFormula        string
+	MonochromeColor         string
+	Invert                  bool
+	HueShift                float64
+	Grain                   float64
+	GrainSeed               int64
+	ToneCurve               []float64
+	DuotoneShadow           string
+	DuotoneHighlight        string
+	AutoContrast            bool
+	AutoLevels              bool
+	AutoLevelsClip          float64
+	ClaheClipLimit          float64
+	ClaheTiles              int
+	Blur                    float64
+	MedianRadius            int
+	Pixelate                int
+	UnsharpRadius           float64
+	UnsharpAmount           float64
+	UnsharpThreshold        float64
+	MaxColors               int
+	Palette                 string
+	ColorMergeTolerance     float64
+	Rotate                  float64
+	RotateFill              string
+	FlipHorizontal          bool
+	FlipVertical            bool
+	Deterministic           bool
+	Progress                ProgressFunc   `json:"-"`
+	OnChunk                 ChunkFunc      `json:"-"`
+	OnLine                  LineFunc       `json:"-"`
+	CharSelector            CharSelectFunc `json:"-"`
+
+	gridFilter  func(Grid)
+	converter   Converter
+	frameLimits *Limits
+	warnings    *warningList
+}
+
+func DefaultOptions() Options {
+	return Options{
+		TargetWidth:         150,
+		Fit:                 FitContain,
+		MaxProcessDimension: DefaultMaxProcessDimension,
+		Resample:            ResampleLanczos,
+		OutputFormat:        OutputSVG,
+		Border:              BorderNone,
+		BorderWidth:         1,
+		CaptionPosition:     CaptionBelow,
+		CaptionOpacity:      1,
+		DiffThreshold:       defaultDiffThreshold,
+		DiffColor:           defaultDiffColor,
+		RasterScale:         defaultRasterScale,
+		FrameDelay:          defaultFrameDelay,
+		CharWidth:           DefaultCharWidth,
+		LineHeight:          DefaultLineHeight,
+		FontSize:            DefaultFontSize,
+		LetterSpacing:       1,
+		LineSpacing:         1,
+		PaddingTop:          DefaultPaddingTop,
+		PaddingBottom:       DefaultPaddingBottom,
+		PaddingLeft:         DefaultPaddingLeft,
+		PaddingRight:        DefaultPaddingRight,
+		BackgroundColor:     "#000000",
+		TransparencyColor:   "#FFFFFF",
+		ChromaKeyTolerance:  defaultChromaKeyTolerance,
+		AutoLevelsClip:      defaultAutoLevelsClip,
+		Charset:             defaultCharset,
+		Mode:                ModeASCII,
+		Dither:              DitherNone,
+		LuminanceFormula:    LuminanceAverage,
+		MonochromeColor:     "#FFFFFF",
+		ClaheTiles:          defaultClaheTiles,
+		UnsharpRadius:       defaultUnsharpRadius,
+		Palette:             PaletteTrueColor,
+	}
+}
+
+func validateOptions(opts Options) error {
+	if opts.TargetWidth <= 0 && opts.OutputWidth == 0 {
+		return NewOptionError("targetWidth", "target width must be positive")
+	}
+	if opts.OutputWidth < 0 {
+		return NewOptionError("outputWidth", "output width must not be negative")
+	}
+	if opts.MaxASCIIDimension != 0 && (opts.MaxASCIIDimension < minLimits.MaxASCIIDimension || opts.MaxASCIIDimension > maxLimits.MaxASCIIDimension) {
+		return NewOptionError("maxASCIIDimension", "max ASCII dimension must be between %d and %d, got %d", minLimits.MaxASCIIDimension, maxLimits.MaxASCIIDimension, opts.MaxASCIIDimension)
+	}
+	if opts.TargetHeight < 0 {
+		return NewOptionError("targetHeight", "target height must not be negative")
+	}
+	if opts.MaxProcessDimension < 0 {
+		return NewOptionError("maxProcessDimension", "max process dimension must not be negative (0 disables the limit)")
+	}
+	if opts.Resample != "" && !slices.Contains(ResampleNames(), opts.Resample) {
+		return NewOptionError("resample", "unknown resample filter %q (valid filters: %s)", opts.Resample, strings.Join(ResampleNames(), ", "))
+	}
+	if opts.OutputFormat != "" && !slices.Contains(OutputFormats, opts.OutputFormat) {
+		return NewOptionError("outputFormat", "unknown output format %q (valid formats: %s)", opts.OutputFormat, strings.Join(OutputFormats, ", "))
+	}
+	if opts.OnLine != nil && !slices.Contains(lineFormats, cmp.Or(opts.OutputFormat, OutputSVG)) {
+		return NewOptionError("onLine", "line callbacks are only supported for %s output", strings.Join(lineFormats, ", "))
+	}
+	if opts.RasterScale != 0 && (opts.RasterScale < 1 || opts.RasterScale > maxRasterScale) {
+		return NewOptionError("rasterScale", "raster scale must be between 1 and %.0f, got %.2f", maxRasterScale, opts.RasterScale)
+	}
+	if opts.FrameDelay != 0 && (opts.FrameDelay < minFrameDelay || opts.FrameDelay > maxFrameDelay) {
+		return NewOptionError("frameDelay", "frame delay must be between %d and %d milliseconds, got %d", minFrameDelay, maxFrameDelay, opts.FrameDelay)
+	}
+	if opts.Preset != "" && !slices.Contains(PresetNames(), opts.Preset) {
+		return NewOptionError("preset", "unknown preset %q (valid presets: %s)", opts.Preset, strings.Join(PresetNames(), ", "))
+	}
+	if opts.Fit != "" && !slices.Contains(FitNames(), opts.Fit) {
+		return NewOptionError("fit", "unknown fit mode %q (valid modes: %s)", opts.Fit, strings.Join(FitNames(), ", "))
+	}
+	if opts.Theme != "" && !slices.Contains(ThemeNames(), opts.Theme) {
+		return NewOptionError("theme", "unknown theme %q (valid themes: %s)", opts.Theme, strings.Join(ThemeNames(), ", "))
+	}
+	if _, ok := charsetPresets[opts.Charset]; opts.Charset != "" && !ok {
+		return NewOptionError("charset", "unknown charset %q (valid charsets: %s)", opts.Charset, strings.Join(CharsetNames(), ", "))
+	}
+	if opts.Mode != "" && !slices.Contains(ModeNames(), opts.Mode) {
+		return NewOptionError("mode", "unknown mode %q (valid modes: %s)", opts.Mode, strings.Join(ModeNames(), ", "))
+	}
+	if opts.Dither != "" && !slices.Contains(DitherNames(), opts.Dither) {
+		return NewOptionError("dither", "unknown dither method %q (valid methods: %s)", opts.Dither, strings.Join(DitherNames(), ", "))
+	}
+	if opts.LuminanceFormula != "" && !slices.Contains(LuminanceFormulaNames(), opts.LuminanceFormula) {
+		return NewOptionError("luminanceFormula", "unknown luminance formula %q (valid formulas: %s)",
+			opts.LuminanceFormula, strings.Join(LuminanceFormulaNames(), ", "))
+	}
+	if opts.MonochromeColor != "" && parseHexColor(opts.MonochromeColor) == nil {
+		return NewOptionError("monochromeColor", "invalid monochrome color %q", opts.MonochromeColor)
+	}
+	if opts.ClaheClipLimit < 0 || opts.ClaheClipLimit > 64 {
+		return NewOptionError("claheClipLimit", "CLAHE clip limit must be between 0 and 64, got %.2f", opts.ClaheClipLimit)
+	}
+	if opts.ClaheTiles != 0 && (opts.ClaheTiles < 1 || opts.ClaheTiles > 64) {
+		return NewOptionError("claheTiles", "CLAHE tile count must be between 1 and 64, got %d", opts.ClaheTiles)
+	}
+	if opts.Brightness < -100 || opts.Brightness > 100 {
+		return NewOptionError("brightness", "brightness must be between -100 and 100, got %.2f", opts.Brightness)
+	}
+	if opts.Contrast < -100 || opts.Contrast > 100 {
+		return NewOptionError("contrast", "contrast must be between -100 and 100, got %.2f", opts.Contrast)
+	}
+	if opts.Sharpen < 0 || opts.Sharpen > 5 {
+		return NewOptionError("sharpen", "sharpen must be between 0 and 5, got %.2f", opts.Sharpen)
+	}
+	if opts.HueShift < -360 || opts.HueShift > 360 {
+		return NewOptionError("hueShift", "hue shift must be between -360 and 360, got %.2f", opts.HueShift)
+	}
+	if opts.Rotate < -360 || opts.Rotate > 360 {
+		return NewOptionError("rotate", "rotation must be between -360 and 360, got %.2f", opts.Rotate)
+	}
+	if opts.Blur < 0 || opts.Blur > 20 {
+		return NewOptionError("blur", "blur sigma must be between 0 and 20, got %.2f", opts.Blur)
+	}
+	if opts.MedianRadius < 0 || opts.MedianRadius > 10 {
+		return NewOptionError("medianRadius", "median radius must be between 0 and 10, got %d", opts.MedianRadius)
+	}
+	if opts.AutoLevelsClip < 0 || opts.AutoLevelsClip > maxAutoLevelsClip {
+		return NewOptionError("autoLevelsClip", "auto levels clip must be between 0 and %d percent, got %.2f", maxAutoLevelsClip, opts.AutoLevelsClip)
+	}
+	if opts.Pixelate < 0 || opts.Pixelate > maxPixelateBlock {
+		return NewOptionError("pixelate", "pixelate block size must be between 0 and %d, got %d", maxPixelateBlock, opts.Pixelate)
+	}
+	if opts.UnsharpRadius < 0 || opts.UnsharpRadius > 20 {
+		return NewOptionError("unsharpRadius", "unsharp radius must be between 0 and 20, got %.2f", opts.UnsharpRadius)
+	}
+	if opts.UnsharpAmount < 0 || opts.UnsharpAmount > 5 {
+		return NewOptionError("unsharpAmount", "unsharp amount must be between 0 and 5, got %.2f", opts.UnsharpAmount)
+	}
+	if opts.UnsharpThreshold < 0 || opts.UnsharpThreshold > 255 {
+		return NewOptionError("unsharpThreshold", "unsharp threshold must be between 0 and 255, got %.2f", opts.UnsharpThreshold)
+	}
+	for _, v := range opts.ToneCurve {
+		if !(v >= 0 && v <= 1) {
+			return NewOptionError("toneCurve", "tone curve values must be numbers between 0 and 1")
+		}
+	}
+	if len(opts.ToneCurve) == 1 || len(opts.ToneCurve) > toneCurveSize {
+		return NewOptionError("toneCurve", "tone curve must have between 2 and %d values, got %d", toneCurveSize, len(opts.ToneCurve))
+	}
+	if opts.MaxColors != 0 && (opts.MaxColors < 2 || opts.MaxColors > 256) {
+		return NewOptionError("maxColors", "max colors must be 0 (disabled) or between 2 and 256, got %d", opts.MaxColors)
+	}
+	if opts.Palette != "" && !slices.Contains(PaletteNames(), opts.Palette) {
+		return NewOptionError("palette", "unknown palette %q (valid palettes: %s)", opts.Palette, strings.Join(PaletteNames(), ", "))
+	}
+	if err := validateCellMetrics(opts); err != nil {
+		return err
+	}
+	if strings.ContainsAny(opts.FontFamily, "{};:<>\\") {
+		return NewOptionError("fontFamily", "font family %q contains invalid characters", opts.FontFamily)
+	}
+	if _, _, ok := embeddedFontFormat(opts.EmbedFont); opts.EmbedFont != nil && !ok {
+		return NewOptionError("embedFont", "embedded font must be WOFF, WOFF2, TrueType or OpenType data")
+	}
+	if opts.BackgroundGradient != "" && parseHexColor(opts.BackgroundGradient) == nil {
+		return NewOptionError("backgroundGradient", "invalid background gradient color %q", opts.BackgroundGradient)
+	}
+	if opts.BackgroundGradientAngle < -360 || opts.BackgroundGradientAngle > 360 {
+		return NewOptionError("backgroundGradientAngle", "background gradient angle must be between -360 and 360, got %.2f", opts.BackgroundGradientAngle)
+	}
+	if opts.Border != "" && !slices.Contains(BorderNames(), opts.Border) {
+		return NewOptionError("border", "unknown border style %q (valid styles: %s)", opts.Border, strings.Join(BorderNames(), ", "))
+	}
+	if opts.BorderColor != "" && parseHexColor(opts.BorderColor) == nil {
+		return NewOptionError("borderColor", "invalid border color %q", opts.BorderColor)
+	}
+	if opts.BorderWidth < 0 || opts.BorderWidth > maxBorderWidth {
+		return NewOptionError("borderWidth", "border width must be between 0 and %d, got %d", maxBorderWidth, opts.BorderWidth)
+	}
+	if opts.BorderPadding < 0 || opts.BorderPadding > maxBorderPadding {
+		return NewOptionError("borderPadding", "border padding must be between 0 and %d, got %d", maxBorderPadding, opts.BorderPadding)
+	}
+	if opts.DiffThreshold < 0 || opts.DiffThreshold > 1 {
+		return NewOptionError("diffThreshold", "diff threshold must be between 0 and 1, got %.2f", opts.DiffThreshold)
+	}
+	if opts.DiffColor != "" && parseHexColor(opts.DiffColor) == nil {
+		return NewOptionError("diffColor", "invalid diff color %q", opts.DiffColor)
+	}
+	if utf8.RuneCountInString(opts.Author) > sauceAuthorLength {
+		return NewOptionError("author", "author must be at most %d characters", sauceAuthorLength)
+	}
+	if utf8.RuneCountInString(opts.Caption) > maxCaptionLength {
+		return NewOptionError("caption", "caption must be at most %d characters", maxCaptionLength)
+	}
+	if opts.CaptionPosition != "" && !slices.Contains(CaptionPositionNames(), opts.CaptionPosition) {
+		return NewOptionError("captionPosition", "unknown caption position %q (valid positions: %s)",
+			opts.CaptionPosition, strings.Join(CaptionPositionNames(), ", "))
+	}
+	if opts.CaptionColor != "" && parseHexColor(opts.CaptionColor) == nil {
+		return NewOptionError("captionColor", "invalid caption color %q", opts.CaptionColor)
+	}
+	if opts.CaptionOpacity < 0 || opts.CaptionOpacity > 1 {
+		return NewOptionError("captionOpacity", "caption opacity must be between 0 and 1, got %.2f", opts.CaptionOpacity)
+	}
+	if opts.OutlineColor != "" && parseHexColor(opts.OutlineColor) == nil {
+		return NewOptionError("outlineColor", "invalid outline color %q", opts.OutlineColor)
+	}
+	if opts.OutlineWidth < 0 || opts.OutlineWidth > maxOutlineWidth {
+		return NewOptionError("outlineWidth", "outline width must be between 0 and %d, got %.2f", maxOutlineWidth, opts.OutlineWidth)
+	}
+	if opts.ShadowColor != "" && parseHexColor(opts.ShadowColor) == nil {
+		return NewOptionError("shadowColor", "invalid shadow color %q", opts.ShadowColor)
+	}
+	if opts.ShadowOffset < -maxShadowOffset || opts.ShadowOffset > maxShadowOffset {
+		return NewOptionError("shadowOffset", "shadow offset must be between %d and %d, got %.2f", -maxShadowOffset, maxShadowOffset, opts.ShadowOffset)
+	}
+	if opts.ShadowBlur < 0 || opts.ShadowBlur > maxShadowBlur {
+		return NewOptionError("shadowBlur", "shadow blur must be between 0 and %d, got %.2f", maxShadowBlur, opts.ShadowBlur)
+	}
+	if opts.DefaultTextColor != "" && parseHexColor(opts.DefaultTextColor) == nil {
+		return NewOptionError("defaultTextColor", "invalid default text color %q", opts.DefaultTextColor)
+	}
+	if opts.Threshold < 0 || opts.Threshold > 1 {
+		return NewOptionError("threshold", "threshold must be between 0 and 1, got %.2f", opts.Threshold)
+	}
+	if opts.ChromaKey != "" && parseHexColor(opts.ChromaKey) == nil {
+		return NewOptionError("chromaKey", "invalid chroma key color %q", opts.ChromaKey)
+	}
+	if opts.ColorMergeTolerance < 0 || opts.ColorMergeTolerance > maxColorMergeTolerance {
+		return NewOptionError("colorMergeTolerance", "color merge tolerance must be between 0 and %d, got %.2f", maxColorMergeTolerance, opts.ColorMergeTolerance)
+	}
+	if opts.Grain < 0 || opts.Grain > 1 {
+		return NewOptionError("grain", "grain must be between 0 and 1, got %.2f", opts.Grain)
+	}
+	if opts.ChromaKeyTolerance < 0 || opts.ChromaKeyTolerance > 1 {
+		return NewOptionError("chromaKeyTolerance", "chroma key tolerance must be between 0 and 1, got %.2f", opts.ChromaKeyTolerance)
+	}
+	if opts.RotateFill != "" && parseHexColor(opts.RotateFill) == nil {
+		return NewOptionError("rotateFill", "invalid rotate fill color %q", opts.RotateFill)
+	}
+	if (opts.DuotoneShadow == "") != (opts.DuotoneHighlight == "") {
+		return NewOptionError("duotoneShadow", "duotone requires both a shadow and a highlight color")
+	}
+	if opts.DuotoneShadow != "" && parseHexColor(opts.DuotoneShadow) == nil {
+		return NewOptionError("duotoneShadow", "invalid duotone shadow color %q", opts.DuotoneShadow)
+	}
+	if opts.DuotoneHighlight != "" && parseHexColor(opts.DuotoneHighlight) == nil {
+		return NewOptionError("duotoneHighlight", "invalid duotone highlight color %q", opts.DuotoneHighlight)
+	}
+	return nil
+}
+
+func (o *Options) setDefaults() {
+	if o.warnings == nil {
+		o.warnings = &warningList{}
+	}
+	o.applyPreset()
+	o.applyTheme()
+	if o.BackgroundColor == "" {
+		o.BackgroundColor = "#000000"
+	}
+	if o.TransparencyColor == "" {
+		o.TransparencyColor = "#FFFFFF"
+	}
+	if o.Fit == "" {
+		o.Fit = FitContain
+	}
+	if o.OutputFormat == "" {
+		o.OutputFormat = OutputSVG
+	}
+	if o.Border == "" {
+		o.Border = BorderNone
+	}
+	if o.DiffColor == "" {
+		o.DiffColor = defaultDiffColor
+	}
+	if o.CaptionPosition == "" {
+		o.CaptionPosition = CaptionBelow
+	}
+	if o.CaptionOpacity == 0 {
+		o.CaptionOpacity = 1
+	}
+	if o.BorderWidth == 0 {
+		o.BorderWidth = 1
+	}
+	if o.RasterScale == 0 {
+		o.RasterScale = defaultRasterScale
+	}
+	if o.FrameDelay == 0 {
+		o.FrameDelay = defaultFrameDelay
+	}
+	if o.Resample == "" {
+		o.Resample = ResampleLanczos
+	}
+	if o.CharWidth == 0 {
+		o.CharWidth = DefaultCharWidth
+	}
+	if o.LineHeight == 0 {
+		o.LineHeight = DefaultLineHeight
+	}
+	if o.Mode == ModeEmoji && o.CharWidth == DefaultCharWidth && o.LineHeight == DefaultLineHeight {
+		o.CharWidth, o.LineHeight = emojiCellSize/2, emojiCellSize
+	}
+	if o.Mode == ModeMosaic && o.PaddingTop == DefaultPaddingTop && o.PaddingBottom == DefaultPaddingBottom &&
+		o.PaddingLeft == DefaultPaddingLeft && o.PaddingRight == DefaultPaddingRight {
+		o.PaddingTop, o.PaddingBottom, o.PaddingLeft, o.PaddingRight = 0, 0, 0, 0
+	}
+	if o.FontSize == 0 {
+		o.FontSize = DefaultFontSize
+	}
+	if o.LetterSpacing == 0 {
+		o.LetterSpacing = 1
+	}
+	if o.LineSpacing == 0 {
+		o.LineSpacing = 1
+	}
+	if o.OutputWidth > 0 {
+		o.TargetWidth = o.columnsForWidth(o.OutputWidth)
+	}
+	if o.Charset == "" {
+		o.Charset = defaultCharset
+	}
+	if o.Mode == "" {
+		o.Mode = ModeASCII
+	}
+	if o.Dither == "" {
+		o.Dither = DitherNone
+	}
+	if o.LuminanceFormula == "" {
+		o.LuminanceFormula = LuminanceAverage
+	}
+	if o.MonochromeColor == "" {
+		o.MonochromeColor = "#FFFFFF"
+	}
+	if o.ClaheTiles == 0 {
+		o.ClaheTiles = defaultClaheTiles
+	}
+	if o.UnsharpRadius == 0 {
+		o.UnsharpRadius = defaultUnsharpRadius
+	}
+	if o.Palette == "" {
+		o.Palette = PaletteTrueColor
+	}
+	if clamped := math.Max(0.0, math.Min(1.0, o.TransparencyThreshold)); clamped != o.TransparencyThreshold {
+		o.warn(WarnClamped, "transparencyThreshold", "transparencyThreshold %g was clamped to %g", o.TransparencyThreshold, clamped)
+		o.TransparencyThreshold = clamped
+	}
+	o.warnIgnoredOptions()
+}
@@ -0,0 +1,25 @@
+package lib
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+)
+
+func DecodeOptionsJSON(r io.Reader, opts *Options) error {
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(opts); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			return NewOptionError(typeErr.Field, "option %q has the wrong type: expected %s, got %s", typeErr.Field, typeErr.Type, typeErr.Value)
+		}
+		if name, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+			name = strings.Trim(name, `"`)
+			return NewOptionError(name, "unknown option %q", name)
+		}
+		return NewError(CodeBadInput, "invalid options JSON: %w", err)
+	}
+	return nil
+}
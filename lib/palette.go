@@ -0,0 +1,129 @@
+package lib
+
+import (
+	"image"
+	"sort"
+
+	"github.com/leaanthony/go-ansi-parser"
+)
+
+const maxPaletteSamples = 65536
+
+func medianCutPalette(img image.Image, n int) [][3]uint8 {
+	src := cloneNRGBA(img)
+	defer releaseNRGBA(src)
+	total := len(src.Pix) / 4
+	step := max(1, total/maxPaletteSamples)
+
+	pixels := make([][3]uint8, 0, total/step+1)
+	for i := 0; i < total; i += step {
+		p := src.Pix[i*4 : i*4+4]
+		if p[3] == 0 {
+			continue
+		}
+		pixels = append(pixels, [3]uint8{p[0], p[1], p[2]})
+	}
+	if len(pixels) == 0 {
+		return [][3]uint8{{0, 0, 0}}
+	}
+
+	boxes := [][][3]uint8{pixels}
+	for len(boxes) < n {
+		idx, channel, spread := -1, 0, 0
+		for i, box := range boxes {
+			if len(box) < 2 {
+				continue
+			}
+			c, s := widestChannel(box)
+			if s > spread {
+				idx, channel, spread = i, c, s
+			}
+		}
+		if idx < 0 {
+			break
+		}
+
+		box := boxes[idx]
+		sort.Slice(box, func(a, b int) bool { return box[a][channel] < box[b][channel] })
+		mid := len(box) / 2
+		boxes[idx] = box[:mid]
+		boxes = append(boxes, box[mid:])
+	}
+
+	palette := make([][3]uint8, len(boxes))
+	for i, box := range boxes {
+		var sum [3]int
+		for _, p := range box {
+			for c := 0; c < 3; c++ {
+				sum[c] += int(p[c])
+			}
+		}
+		for c := 0; c < 3; c++ {
+			palette[i][c] = uint8(sum[c] / len(box))
+		}
+	}
+	return palette
+}
+
+func widestChannel(box [][3]uint8) (int, int) {
+	lo := [3]uint8{255, 255, 255}
+	var hi [3]uint8
+	for _, p := range box {
+		for c := 0; c < 3; c++ {
+			lo[c] = min(lo[c], p[c])
+			hi[c] = max(hi[c], p[c])
+		}
+	}
+
+	channel, spread := 0, 0
+	for c := 0; c < 3; c++ {
+		if s := int(hi[c]) - int(lo[c]); s > spread {
+			channel, spread = c, s
+		}
+	}
+	return channel, spread
+}
+
+func paletteMapper(palette [][3]uint8) colorMapper {
+	return func(r, g, b uint8) (uint8, uint8, uint8) {
+		c := nearestPaletteColor(palette, [3]uint8{r, g, b})
+		return c[0], c[1], c[2]
+	}
+}
+
+func nearestPaletteColor(palette [][3]uint8, c [3]uint8) [3]uint8 {
+	return palette[nearestPaletteIndex(palette, c)]
+}
+
+func nearestPaletteIndex(palette [][3]uint8, c [3]uint8) int {
+	best, bestDist := 0, -1
+	for i, p := range palette {
+		if d := colorDistance(p, c); bestDist < 0 || d < bestDist {
+			best, bestDist = i, d
+		}
+	}
+	return best
+}
+
+const (
+	PaletteTrueColor = "truecolor"
+	PaletteANSI256   = "ansi256"
+	PaletteANSI16    = "ansi16"
+)
+
+func PaletteNames() []string {
+	return []string{PaletteTrueColor, PaletteANSI256, PaletteANSI16}
+}
+
+func terminalPalette(name string) [][3]uint8 {
+	size := 256
+	if name == PaletteANSI16 {
+		size = 16
+	}
+
+	palette := make([][3]uint8, 0, size)
+	for _, col := range ansi.Cols[:size] {
+		palette = append(palette, [3]uint8{col.Rgb.R, col.Rgb.G, col.Rgb.B})
+	}
+	return palette
+}
@@ -0,0 +1,130 @@
+package lib
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestTerminalPalette(t *testing.T) {
+	tests := []struct {
+		name string
+		size int
+	}{
+		{PaletteANSI256, 256},
+		{PaletteANSI16, 16},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := len(terminalPalette(tt.name)); got != tt.size {
+				t.Errorf("len = %d, want %d", got, tt.size)
+			}
+		})
+	}
+}
+
+func TestNearestPaletteColor(t *testing.T) {
+	palette := terminalPalette(PaletteANSI256)
+	tests := []struct {
+		name  string
+		color [3]uint8
+		want  [3]uint8
+	}{
+		{"black", [3]uint8{0, 0, 0}, [3]uint8{0, 0, 0}},
+		{"white", [3]uint8{255, 255, 255}, [3]uint8{255, 255, 255}},
+		{"cube step", [3]uint8{100, 0, 0}, [3]uint8{95, 0, 0}},
+		{"rounds up", [3]uint8{250, 120, 0}, [3]uint8{255, 135, 0}},
+		{"gray ramp", [3]uint8{100, 100, 100}, [3]uint8{98, 98, 98}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := nearestPaletteColor(palette, tt.color); got != tt.want {
+				t.Errorf("nearestPaletteColor(%v) = %v, want %v", tt.color, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMedianCutPalette(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 64, 64))
+	for y := 0; y < 64; y++ {
+		for x := 0; x < 64; x++ {
+			img.SetNRGBA(x, y, color.NRGBA{uint8(x * 4), uint8(y * 4), 128, 255})
+		}
+	}
+	for _, n := range []int{1, 4, 16} {
+		if got := medianCutPalette(img, n); len(got) == 0 || len(got) > n {
+			t.Errorf("medianCutPalette(%d) returned %d colors", n, len(got))
+		}
+	}
+}
+
+func TestProcessImagePalettes(t *testing.T) {
+	image := readFixture(t, "video-001.png")
+	tests := []struct {
+		name      string
+		palette   string
+		maxColors int
+		allowed   [][3]uint8
+		maxUnique int
+	}{
+		{"ansi16", PaletteANSI16, 0, terminalPalette(PaletteANSI16), 16},
+		{"ansi256", PaletteANSI256, 0, terminalPalette(PaletteANSI256), 256},
+		{"max colors", PaletteTrueColor, 4, nil, 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := DefaultOptions()
+			opts.Mode = ModeHalfBlock
+			opts.OutputFormat = OutputANSI
+			opts.TargetWidth = 24
+			opts.Palette = tt.palette
+			opts.MaxColors = tt.maxColors
+
+			result, err := ProcessImage(image, opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			allowed := make(map[[3]uint8]bool)
+			for _, c := range tt.allowed {
+				allowed[c] = true
+			}
+			unique := make(map[[3]uint8]bool)
+			for _, cell := range ansiCellColors(result.Text) {
+				for _, c := range [][3]uint8{
+					{uint8(cell[0]), uint8(cell[1]), uint8(cell[2])},
+					{uint8(cell[3]), uint8(cell[4]), uint8(cell[5])},
+				} {
+					if tt.allowed != nil && !allowed[c] {
+						t.Fatalf("color %v is not in the %s palette", c, tt.palette)
+					}
+					unique[c] = true
+				}
+			}
+			if len(unique) > tt.maxUnique {
+				t.Errorf("got %d distinct colors, want at most %d", len(unique), tt.maxUnique)
+			}
+		})
+	}
+}
+
+func TestDefaultASCIIUsesANSI256(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 32, 32))
+	for i := range img.Pix {
+		img.Pix[i] = uint8(i * 13)
+	}
+	palette := make(map[[3]uint8]bool)
+	for _, c := range terminalPalette(PaletteANSI256) {
+		palette[c] = true
+	}
+
+	opts := DefaultOptions()
+	opts.setDefaults()
+	for _, row := range renderASCII(img, 16, 16, opts) {
+		for _, cell := range row {
+			if c := [3]uint8{cell.FG.R, cell.FG.G, cell.FG.B}; !palette[c] {
+				t.Fatalf("foreground %v is not an ANSI 256 color", c)
+			}
+		}
+	}
+}
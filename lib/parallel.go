@@ -0,0 +1,28 @@
+package lib
+
+import (
+	"runtime"
+	"sync"
+)
+
+const minRowsPerWorker = 16
+
+func parallelRows(minY, maxY int, fn func(y0, y1 int)) {
+	rows := maxY - minY
+	workers := min(runtime.GOMAXPROCS(0), rows/minRowsPerWorker)
+	if workers <= 1 {
+		fn(minY, maxY)
+		return
+	}
+
+	var wg sync.WaitGroup
+	chunk := (rows + workers - 1) / workers
+	for y0 := minY; y0 < maxY; y0 += chunk {
+		wg.Add(1)
+		go func(y0, y1 int) {
+			defer wg.Done()
+			fn(y0, y1)
+		}(y0, min(y0+chunk, maxY))
+	}
+	wg.Wait()
+}
@@ -0,0 +1,183 @@
+package lib
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"math"
+
+	"github.com/leaanthony/go-ansi-parser"
+)
+
+const (
+	pdfPointsPerPixel = 0.75
+	pdfCourierAdvance = 0.6
+	pdfBaselineRatio  = 0.8
+	pdfBezierCircle   = 0.5523
+)
+
+func renderToPDF(styledText []*ansi.StyledText, opts Options) ([]byte, error) {
+	if styledText == nil {
+		return nil, NewError(CodeRender, "styledText is nil")
+	}
+
+	lines := splitStyledTextByLine(styledText)
+	m := opts.cellMetrics()
+	width, height := calculateSVGDimensions(lines, m)
+	inset := borderInset(opts)
+	width, height = width+2*inset, height+2*inset
+	frameHeight := height
+	height += captionExtraHeight(opts, m)
+	pageWidth, pageHeight := float64(width)*pdfPointsPerPixel, float64(height)*pdfPointsPerPixel
+
+	var content bytes.Buffer
+	fmt.Fprintf(&content, "%.2f 0 0 %.2f 0 %.2f cm\n", pdfPointsPerPixel, -pdfPointsPerPixel, pageHeight)
+	background := hexToRGB(opts.BackgroundColor)
+	shading := pdfBackground(&content, opts, width, height)
+
+	for _, r := range borderRects(opts, width, frameHeight) {
+		pdfFillRect(&content, hexToRGB(borderColor(opts)), r[0], r[1], r[2], r[3])
+	}
+	fmt.Fprintf(&content, "q 1 0 0 1 %d %d cm\n", inset, inset)
+	painter := &pdfPainter{w: &content, metrics: m}
+	paintCells(painter, lines, m, background, fallbackTextColor(opts))
+	content.WriteString("Q\n")
+
+	if opts.Caption != "" {
+		left, top := captionOrigin(opts, m, width, height)
+		content.WriteString("q /GS1 gs\n")
+		for i, char := range []rune(opts.Caption) {
+			painter.drawText(char, hexToRGB(captionColor(opts)), float64(left+i*m.charWidth), float64(top+m.paddingTop))
+		}
+		content.WriteString("Q\n")
+	}
+	if painter.unsupported != 0 {
+		return nil, NewError(CodeRender, "PDF output cannot draw the glyph %q (U+%04X)", painter.unsupported, painter.unsupported)
+	}
+
+	return buildPDF(pageWidth, pageHeight, opts.CaptionOpacity, shading, content.Bytes())
+}
+
+func pdfBackground(w *bytes.Buffer, opts Options, width, height int) string {
+	from := hexToRGB(opts.BackgroundColor)
+	if opts.BackgroundGradient == "" {
+		pdfFillRect(w, from, 0, 0, float64(width), float64(height))
+		return ""
+	}
+
+	to := hexToRGB(opts.BackgroundGradient)
+	angle := opts.BackgroundGradientAngle * math.Pi / 180
+	dx, dy := math.Cos(angle)/2, math.Sin(angle)/2
+	fmt.Fprintf(w, "q %d 0 0 %d 0 0 cm 0 0 1 1 re W n /Sh1 sh Q\n", width, height)
+	return fmt.Sprintf("<< /ShadingType 2 /ColorSpace /DeviceRGB /Coords [%.4f %.4f %.4f %.4f] /Function << /FunctionType 2 /Domain [0 1] /C0 [%s] /C1 [%s] /N 1 >> /Extend [true true] >>",
+		0.5-dx, 0.5-dy, 0.5+dx, 0.5+dy, pdfColor(from), pdfColor(to))
+}
+
+type pdfPainter struct {
+	w           *bytes.Buffer
+	metrics     cellMetrics
+	unsupported rune
+}
+
+func (p *pdfPainter) fillRect(c [3]uint8, x, y, width, height float64) {
+	pdfFillRect(p.w, c, x, y, width, height)
+}
+
+func (p *pdfPainter) fillCircle(c [3]uint8, centerX, centerY, radius float64) {
+	k := radius * pdfBezierCircle
+	fmt.Fprintf(p.w, "%s rg %.2f %.2f m\n", pdfColor(c), centerX+radius, centerY)
+	fmt.Fprintf(p.w, "%.2f %.2f %.2f %.2f %.2f %.2f c\n", centerX+radius, centerY+k, centerX+k, centerY+radius, centerX, centerY+radius)
+	fmt.Fprintf(p.w, "%.2f %.2f %.2f %.2f %.2f %.2f c\n", centerX-k, centerY+radius, centerX-radius, centerY+k, centerX-radius, centerY)
+	fmt.Fprintf(p.w, "%.2f %.2f %.2f %.2f %.2f %.2f c\n", centerX-radius, centerY-k, centerX-k, centerY-radius, centerX, centerY-radius)
+	fmt.Fprintf(p.w, "%.2f %.2f %.2f %.2f %.2f %.2f c f\n", centerX+k, centerY-radius, centerX+radius, centerY-k, centerX+radius, centerY)
+}
+
+func (p *pdfPainter) drawText(char rune, c [3]uint8, left, top float64) {
+	b, ok := winAnsiByte(char)
+	if !ok {
+		if p.unsupported == 0 {
+			p.unsupported = char
+		}
+		return
+	}
+
+	m := p.metrics
+	scale := float64(m.glyphWidth) / (pdfCourierAdvance * float64(m.fontSize)) * 100
+	baseline := top + float64(m.fontSize)*pdfBaselineRatio
+	fmt.Fprintf(p.w, "BT /F1 %d Tf %.2f Tz %s rg 1 0 0 -1 %.2f %.2f Tm (%s) Tj ET\n",
+		m.fontSize, scale, pdfColor(c), left, baseline, pdfEscape(b))
+}
+
+var winAnsiExtras = map[rune]byte{
+	'€': 0x80, '‚': 0x82, 'ƒ': 0x83, '„': 0x84, '…': 0x85, '†': 0x86, '‡': 0x87,
+	'ˆ': 0x88, '‰': 0x89, 'Š': 0x8A, '‹': 0x8B, 'Œ': 0x8C, 'Ž': 0x8E, '‘': 0x91,
+	'’': 0x92, '“': 0x93, '”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97, '˜': 0x98,
+	'™': 0x99, 'š': 0x9A, '›': 0x9B, 'œ': 0x9C, 'ž': 0x9E, 'Ÿ': 0x9F,
+}
+
+func winAnsiByte(char rune) (byte, bool) {
+	if char < 0x80 || char >= 0xA0 && char <= 0xFF {
+		return byte(char), true
+	}
+	b, ok := winAnsiExtras[char]
+	return b, ok
+}
+
+func pdfEscape(b byte) string {
+	switch b {
+	case '(', ')', '\\':
+		return `\` + string(b)
+	}
+	return string(b)
+}
+
+func pdfColor(c [3]uint8) string {
+	return fmt.Sprintf("%.3f %.3f %.3f", float64(c[0])/255, float64(c[1])/255, float64(c[2])/255)
+}
+
+func pdfFillRect(w *bytes.Buffer, c [3]uint8, x, y, width, height float64) {
+	fmt.Fprintf(w, "%s rg %.2f %.2f %.2f %.2f re f\n", pdfColor(c), x, y, width, height)
+}
+
+func buildPDF(pageWidth, pageHeight, captionOpacity float64, shading string, content []byte) ([]byte, error) {
+	var compressed bytes.Buffer
+	zw := zlib.NewWriter(&compressed)
+	if _, err := zw.Write(content); err != nil {
+		return nil, NewError(CodeRender, "failed to compress PDF content: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return nil, NewError(CodeRender, "failed to compress PDF content: %w", err)
+	}
+
+	shadings := ""
+	if shading != "" {
+		shadings = " /Shading << /Sh1 6 0 R >>"
+	}
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.2f %.2f] /Resources << /Font << /F1 4 0 R >> /ExtGState << /GS1 << /ca %.3f >> >>%s >> /Contents 5 0 R >>",
+			pageWidth, pageHeight, captionOpacity, shadings),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Courier /Encoding /WinAnsiEncoding >>",
+		fmt.Sprintf("<< /Length %d /Filter /FlateDecode >>\nstream\n%s\nendstream", compressed.Len(), compressed.String()),
+	}
+	if shading != "" {
+		objects = append(objects, shading)
+	}
+
+	var out bytes.Buffer
+	out.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	offsets := make([]int, len(objects))
+	for i, object := range objects {
+		offsets[i] = out.Len()
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+	return out.Bytes(), nil
+}
@@ -0,0 +1,46 @@
+package lib
+
+import (
+	"image"
+)
+
+const maxPixelateBlock = 256
+
+func pixelate(img image.Image, block int) *image.NRGBA {
+	dst := cloneNRGBA(img)
+	width, height := dst.Rect.Dx(), dst.Rect.Dy()
+
+	for by := 0; by < height; by += block {
+		for bx := 0; bx < width; bx += block {
+			x1, y1 := min(bx+block, width), min(by+block, height)
+			var sum [3]int
+			alpha, count := 0, 0
+			for y := by; y < y1; y++ {
+				row := dst.Pix[y*dst.Stride:]
+				for x := bx; x < x1; x++ {
+					p := row[x*4 : x*4+4]
+					for c := 0; c < 3; c++ {
+						sum[c] += int(p[c]) * int(p[3])
+					}
+					alpha += int(p[3])
+					count++
+				}
+			}
+
+			var avg [4]uint8
+			if alpha > 0 {
+				for c := 0; c < 3; c++ {
+					avg[c] = uint8(sum[c] / alpha)
+				}
+				avg[3] = uint8(alpha / count)
+			}
+			for y := by; y < y1; y++ {
+				row := dst.Pix[y*dst.Stride:]
+				for x := bx; x < x1; x++ {
+					copy(row[x*4:x*4+4], avg[:])
+				}
+			}
+		}
+	}
+	return dst
+}
@@ -0,0 +1,51 @@
+package lib
+
+import (
+	"image"
+	"sync"
+
+	"github.com/disintegration/imaging"
+)
+
+const maxPooledImageBytes = DefaultMaxProcessDimension * DefaultMaxProcessDimension * 4
+
+var nrgbaPool = sync.Pool{
+	New: func() interface{} {
+		return new(image.NRGBA)
+	},
+}
+
+func getNRGBA(width, height int) *image.NRGBA {
+	img := nrgbaPool.Get().(*image.NRGBA)
+	size := width * height * 4
+	if cap(img.Pix) < size {
+		img.Pix = make([]uint8, size)
+	}
+	img.Pix = img.Pix[:size]
+	img.Stride = width * 4
+	img.Rect = image.Rect(0, 0, width, height)
+	return img
+}
+
+func releaseNRGBA(img *image.NRGBA) {
+	if img == nil || cap(img.Pix) > maxPooledImageBytes {
+		return
+	}
+	nrgbaPool.Put(img)
+}
+
+func cloneNRGBA(img image.Image) *image.NRGBA {
+	src, ok := img.(*image.NRGBA)
+	if !ok {
+		return imaging.Clone(img)
+	}
+
+	bounds := src.Bounds()
+	dst := getNRGBA(bounds.Dx(), bounds.Dy())
+	rowBytes := bounds.Dx() * 4
+	for y := 0; y < bounds.Dy(); y++ {
+		i := src.PixOffset(bounds.Min.X, bounds.Min.Y+y)
+		copy(dst.Pix[y*dst.Stride:y*dst.Stride+rowBytes], src.Pix[i:i+rowBytes])
+	}
+	return dst
+}
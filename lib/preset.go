@@ -0,0 +1,43 @@
+package lib
+
+const (
+	PresetFast     = "fast"
+	PresetBalanced = "balanced"
+	PresetHigh     = "high"
+)
+
+type preset struct {
+	resample            string
+	maxProcessDimension int
+	dither              string
+	colorMergeTolerance float64
+}
+
+var presets = map[string]preset{
+	PresetFast:     {resample: ResampleBox, maxProcessDimension: 512, dither: DitherNone, colorMergeTolerance: 6},
+	PresetBalanced: {resample: ResampleLanczos, maxProcessDimension: DefaultMaxProcessDimension, dither: DitherNone},
+	PresetHigh:     {resample: ResampleLanczos, maxProcessDimension: 2048, dither: DitherFloydSteinberg},
+}
+
+func PresetNames() []string {
+	return []string{PresetFast, PresetBalanced, PresetHigh}
+}
+
+func (o *Options) applyPreset() {
+	p, ok := presets[o.Preset]
+	if !ok {
+		return
+	}
+	if o.Resample == "" || o.Resample == ResampleLanczos {
+		o.Resample = p.resample
+	}
+	if o.MaxProcessDimension == DefaultMaxProcessDimension {
+		o.MaxProcessDimension = p.maxProcessDimension
+	}
+	if o.Dither == "" || o.Dither == DitherNone {
+		o.Dither = p.dither
+	}
+	if o.ColorMergeTolerance == 0 {
+		o.ColorMergeTolerance = p.colorMergeTolerance
+	}
+}
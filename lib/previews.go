@@ -0,0 +1,62 @@
+package lib
+
+import (
+	"slices"
+	"time"
+)
+
+const MaxPreviewWidths = 8
+
+func ProcessImageWidths(imageData []byte, widths []int, opts Options) ([]*Result, error) {
+	start := time.Now()
+	if len(widths) == 0 || len(widths) > MaxPreviewWidths {
+		return nil, NewError(CodeBadInput, "expected between 1 and %d target widths, got %d", MaxPreviewWidths, len(widths))
+	}
+	if opts.OnChunk != nil {
+		return nil, NewOptionError("onChunk", "streaming output is not supported when rendering multiple widths")
+	}
+	if opts.OnLine != nil {
+		return nil, NewOptionError("onLine", "line callbacks are not supported when rendering multiple widths")
+	}
+	if err := validateImageData(imageData); err != nil {
+		return nil, err
+	}
+	for _, width := range widths {
+		preview := opts
+		preview.TargetWidth = width
+		if err := validateOptions(preview); err != nil {
+			return nil, err
+		}
+	}
+	opts.TargetWidth = slices.Max(widths)
+	opts.setDefaults()
+	if err := checkImageMemory(imageData, opts); err != nil {
+		return nil, err
+	}
+
+	opts.reportProgress(StageDecoding, 0)
+	img, format, err := decodeImage(imageData)
+	if err != nil {
+		return nil, err
+	}
+
+	opts.reportProgress(StageResizing, 20)
+	processedImg := adjustImage(opts.downscale(img), opts)
+	defer releaseAdjusted(processedImg)
+
+	results := make([]*Result, 0, len(widths))
+	for i, width := range widths {
+		preview := opts
+		preview.TargetWidth = width
+		preview.Progress = nil
+		preview.warnings = &warningList{items: opts.collectedWarnings()}
+		result, err := renderAdjusted(processedImg, format, start, preview)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+		opts.reportProgress(StageRendering, 50+50*float64(i+1)/float64(len(widths)))
+	}
+	opts.reportProgress(StageDone, 100)
+	return results, nil
+}
@@ -0,0 +1,25 @@
+package lib
+
+import "testing"
+
+func TestProcessImageWidthsKeepsWarningsPerPreview(t *testing.T) {
+	results, err := ProcessImageWidths(readFixture(t, "video-001.png"), []int{DefaultMaxASCIIDimension + 100, 20}, DefaultOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hasClamp := func(r *Result) bool {
+		for _, w := range r.Warnings {
+			if w.Code == WarnClamped {
+				return true
+			}
+		}
+		return false
+	}
+	if !hasClamp(results[0]) {
+		t.Errorf("wide preview warnings = %v, want %s", results[0].Warnings, WarnClamped)
+	}
+	if hasClamp(results[1]) {
+		t.Errorf("narrow preview warnings = %v, want no %s", results[1].Warnings, WarnClamped)
+	}
+}
@@ -2,28 +2,24 @@ package lib
 
 import (
 	"bytes"
+	"cmp"
+	"encoding/xml"
 	"fmt"
 	"image"
 	"image/color"
 	_ "image/jpeg"
 	_ "image/png"
+	"io"
 	"math"
 	"strconv"
 	"strings"
 	"sync"
-	"syscall/js"
+	"time"
+	"unicode/utf8"
 
 	"github.com/ajstarks/svgo"
 	"github.com/disintegration/imaging"
 	"github.com/leaanthony/go-ansi-parser"
-	"github.com/qeesung/image2ascii/convert"
-)
-
-const (
-	MaxImageSize      = 50 * 1024 * 1024
-	MaxOutputSize     = 10 * 1024 * 1024
-	MaxASCIIChars     = 5000000
-	MaxASCIIDimension = 500
 )
 
 var bufferPool = sync.Pool{
@@ -32,99 +28,290 @@ var bufferPool = sync.Pool{
 	},
 }
 
-type Options struct {
-	TargetWidth           int
-	Brightness            float64
-	Contrast              float64
-	Sharpen               float64
-	BackgroundColor       string
-	TransparencyColor     string
-	TransparencyThreshold float64
+type Result struct {
+	SVG          string
+	Text         string
+	Data         []byte
+	ASCIIWidth   int
+	ASCIIHeight  int
+	CharCount    int
+	ChangedCells int
+	Elapsed      time.Duration
+	Format       string
+	OutputFormat string
+	Warnings     []Warning
 }
 
 func ProcessImageToSVG(imageData []byte, opts Options) (string, error) {
-	if err := validateInput(imageData, opts); err != nil {
+	result, err := ProcessImage(imageData, opts)
+	if err != nil {
 		return "", err
 	}
+	return result.SVG, nil
+}
+
+func ProcessImage(imageData []byte, opts Options) (*Result, error) {
+	start := time.Now()
+	if err := validateInput(imageData, opts); err != nil {
+		return nil, err
+	}
 	opts.setDefaults()
+	if err := checkImageMemory(imageData, opts); err != nil {
+		return nil, err
+	}
 
+	opts.reportProgress(StageDecoding, 0)
 	img, format, err := decodeImage(imageData)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	fmt.Printf("Image decoded successfully. Format: %s\n", format)
+	Logf(LevelDebug, "Image decoded successfully. Format: %s", format)
 
-	processedImg := processImage(img, opts)
+	return processDecodedImage(img, format, start, opts)
+}
 
-	asciiString, err := convertToASCII(processedImg, opts.TargetWidth)
+func ProcessPixelsToSVG(pixels []byte, width, height int, opts Options) (string, error) {
+	result, err := ProcessPixels(pixels, width, height, opts)
 	if err != nil {
 		return "", err
 	}
+	return result.SVG, nil
+}
+
+func ProcessPixels(pixels []byte, width, height int, opts Options) (*Result, error) {
+	start := time.Now()
+	if width <= 0 || height <= 0 {
+		return nil, NewError(CodeBadInput, "invalid image dimensions: %dx%d", width, height)
+	}
+	if err := ValidateImagePixels(width, height); err != nil {
+		return nil, err
+	}
+	if len(pixels) != width*height*4 {
+		return nil, NewError(CodeBadInput, "pixel data length mismatch: got %d bytes, expected %d for %dx%d RGBA", len(pixels), width*height*4, width, height)
+	}
+	if err := validateInput(pixels, opts); err != nil {
+		return nil, err
+	}
+	opts.setDefaults()
+	if err := checkMemoryBudget(estimateMemory(width, height, 4, false, opts)); err != nil {
+		return nil, err
+	}
+
+	img := &image.NRGBA{
+		Pix:    pixels,
+		Stride: width * 4,
+		Rect:   image.Rect(0, 0, width, height),
+	}
+	return processDecodedImage(img, "rgba", start, opts)
+}
+
+func processDecodedImage(img image.Image, format string, start time.Time, opts Options) (*Result, error) {
+	opts.reportProgress(StageResizing, 20)
+	return renderImage(opts.downscale(img), format, start, opts)
+}
+
+func renderImage(img image.Image, format string, start time.Time, opts Options) (*Result, error) {
+	processedImg := adjustImage(img, opts)
+	defer releaseAdjusted(processedImg)
+	return renderAdjusted(processedImg, format, start, opts)
+}
+
+func releaseAdjusted(img image.Image) {
+	if pooled, ok := img.(*image.NRGBA); ok {
+		releaseNRGBA(pooled)
+	}
+}
 
-	styledText, err := parseANSI(asciiString)
+func renderAdjusted(processedImg image.Image, format string, start time.Time, opts Options) (*Result, error) {
+	styledText, asciiWidth, asciiHeight, err := buildStyledText(processedImg, opts)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
+	return renderStyledText(styledText, asciiWidth, asciiHeight, format, start, opts)
+}
 
-	svgString, err := renderToSVG(styledText, opts.BackgroundColor)
+func buildStyledText(processedImg image.Image, opts Options) ([]*ansi.StyledText, int, int, error) {
+	opts.reportProgress(StageASCII, 50)
+	styledText, asciiWidth, asciiHeight, err := convertToASCII(processedImg, opts, opts.limits())
 	if err != nil {
-		return "", err
+		return nil, 0, 0, err
+	}
+	if len(styledText) > largeStyledElements {
+		opts.warn(WarnLargeOutput, "", "large number of styled text elements: %d, processing may be slower", len(styledText))
+	}
+	remapOutputColors(styledText, outputColorMappers(processedImg, opts))
+	if opts.TrimWhitespace {
+		if trimmed, width, height, ok := trimWhitespace(styledText); ok {
+			Logf(LevelDebug, "Trimmed output from %dx%d to %dx%d", asciiWidth, asciiHeight, width, height)
+			styledText, asciiWidth, asciiHeight = trimmed, width, height
+		}
+	}
+	return addTextBorder(styledText, opts), asciiWidth, asciiHeight, nil
+}
+
+func renderStyledText(styledText []*ansi.StyledText, asciiWidth, asciiHeight int, format string, start time.Time, opts Options) (*Result, error) {
+	limits := opts.limits()
+	opts.checkOutputWidth(styledText)
+	result := &Result{
+		ASCIIWidth:   asciiWidth,
+		ASCIIHeight:  asciiHeight,
+		CharCount:    asciiWidth * asciiHeight,
+		Format:       format,
+		OutputFormat: opts.OutputFormat,
+		Warnings:     opts.collectedWarnings(),
 	}
 
-	if len(svgString) > MaxOutputSize {
-		return "", fmt.Errorf("output SVG is too large: %d bytes (max: %d)", len(svgString), MaxOutputSize)
+	if fontSize := embeddedFontSize(opts.EmbedFont); fontSize > limits.MaxOutputSize {
+		return nil, newLimitError(fontSize, limits.MaxOutputSize, "embedded font is too large: %d bytes encoded (max output: %d)", fontSize, limits.MaxOutputSize)
+	}
+
+	opts.reportProgress(StageRendering, 75)
+	if opts.OnChunk != nil {
+		if err := streamOutput(styledText, opts); err != nil {
+			return nil, err
+		}
+		result.Elapsed = opts.elapsedSince(start)
+		opts.reportProgress(StageDone, 100)
+		return result, nil
 	}
 
-	return svgString, nil
+	switch opts.OutputFormat {
+	case OutputSVGZ:
+		data, err := renderToSVGZ(styledText, opts)
+		if err != nil {
+			return nil, err
+		}
+		if len(data) > limits.MaxOutputSize {
+			return nil, newLimitError(len(data), limits.MaxOutputSize, "compressed SVG is too large: %d bytes (max: %d)", len(data), limits.MaxOutputSize)
+		}
+		result.Data = data
+	case OutputPDF:
+		data, err := renderToPDF(styledText, opts)
+		if err != nil {
+			return nil, err
+		}
+		if len(data) > limits.MaxOutputSize {
+			return nil, newLimitError(len(data), limits.MaxOutputSize, "output PDF is too large: %d bytes (max: %d)", len(data), limits.MaxOutputSize)
+		}
+		result.Data = data
+	case OutputPNG:
+		data, err := renderToPNG(styledText, opts)
+		if err != nil {
+			return nil, err
+		}
+		if len(data) > limits.MaxOutputSize {
+			return nil, newLimitError(len(data), limits.MaxOutputSize, "output PNG is too large: %d bytes (max: %d)", len(data), limits.MaxOutputSize)
+		}
+		result.Data = data
+	case OutputGIF:
+		data, err := renderToGIF(styledText, opts)
+		if err != nil {
+			return nil, err
+		}
+		if len(data) > limits.MaxOutputSize {
+			return nil, newLimitError(len(data), limits.MaxOutputSize, "output GIF is too large: %d bytes (max: %d)", len(data), limits.MaxOutputSize)
+		}
+		result.Data = data
+	case OutputANS:
+		data, err := renderToANS(styledText, opts)
+		if err != nil {
+			return nil, err
+		}
+		if len(data) > limits.MaxOutputSize {
+			return nil, newLimitError(len(data), limits.MaxOutputSize, "output ANS is too large: %d bytes (max: %d)", len(data), limits.MaxOutputSize)
+		}
+		result.Data = data
+	case OutputANSI, OutputText, OutputIRC:
+		text, err := renderToText(styledText, opts)
+		if err != nil {
+			return nil, err
+		}
+		if len(text) > limits.MaxOutputSize {
+			return nil, newLimitError(len(text), limits.MaxOutputSize, "text output is too large: %d bytes (max: %d)", len(text), limits.MaxOutputSize)
+		}
+		result.Text = text
+	case OutputITerm2, OutputKitty:
+		text, err := renderToInlineImage(styledText, opts)
+		if err != nil {
+			return nil, err
+		}
+		if len(text) > limits.MaxOutputSize {
+			return nil, newLimitError(len(text), limits.MaxOutputSize, "inline image output is too large: %d bytes (max: %d)", len(text), limits.MaxOutputSize)
+		}
+		result.Text = text
+	default:
+		svgString, err := renderToSVG(styledText, opts)
+		if err != nil {
+			return nil, err
+		}
+		if len(svgString) > limits.MaxOutputSize {
+			return nil, newLimitError(len(svgString), limits.MaxOutputSize, "output SVG is too large: %d bytes (max: %d)", len(svgString), limits.MaxOutputSize)
+		}
+		result.SVG = svgString
+	}
+
+	result.Elapsed = opts.elapsedSince(start)
+	opts.reportProgress(StageDone, 100)
+	return result, nil
 }
 
 func validateInput(imageData []byte, opts Options) error {
-	if len(imageData) == 0 {
-		return fmt.Errorf("image data is empty")
+	if err := validateImageData(imageData); err != nil {
+		return err
 	}
-	if len(imageData) > MaxImageSize {
-		return fmt.Errorf("image data is too large: %d bytes (max: %d)", len(imageData), MaxImageSize)
+	return validateOptions(opts)
+}
+
+func validateImageData(imageData []byte) error {
+	if len(imageData) == 0 {
+		return NewError(CodeBadInput, "image data is empty")
 	}
-	if opts.TargetWidth <= 0 {
-		return fmt.Errorf("target width must be positive")
+	if maxImageSize := CurrentLimits().MaxImageSize; len(imageData) > maxImageSize {
+		return newLimitError(len(imageData), maxImageSize, "image data is too large: %d bytes (max: %d)", len(imageData), maxImageSize)
 	}
 	return nil
 }
 
-func (o *Options) setDefaults() {
-	if o.BackgroundColor == "" {
-		o.BackgroundColor = "#000000"
-	}
-	if o.TransparencyColor == "" {
-		o.TransparencyColor = "#FFFFFF"
+func ValidateImagePixels(width, height int) error {
+	maxPixels := CurrentLimits().MaxImagePixels
+	if pixels := width * height; pixels > maxPixels {
+		return newLimitError(pixels, maxPixels, "image dimensions are too large: %dx%d pixels (max: %s pixels)", width, height, formatNumber(maxPixels))
 	}
-	o.TransparencyThreshold = math.Max(0.0, math.Min(1.0, o.TransparencyThreshold))
+	return nil
 }
 
 func decodeImage(imageData []byte) (image.Image, string, error) {
-	buffer := bytes.NewReader(imageData)
-	img, format, err := image.Decode(buffer)
+	config, format, err := image.DecodeConfig(bytes.NewReader(imageData))
 	if err != nil {
-		return nil, "", fmt.Errorf("failed to decode image: %w", err)
+		return nil, "", NewError(CodeDecode, "failed to decode image: %w", err)
+	}
+	if err := ValidateImagePixels(config.Width, config.Height); err != nil {
+		return nil, "", err
+	}
+	img, err := imaging.Decode(bytes.NewReader(imageData), imaging.AutoOrientation(true))
+	if err != nil {
+		return nil, "", NewError(CodeDecode, "failed to decode image: %w", err)
 	}
 	if img == nil {
-		return nil, "", fmt.Errorf("decoded image is nil")
+		return nil, "", NewError(CodeDecode, "decoded image is nil")
 	}
 
 	bounds := img.Bounds()
 	if bounds.Dx() <= 0 || bounds.Dy() <= 0 {
-		return nil, "", fmt.Errorf("invalid image dimensions: %dx%d", bounds.Dx(), bounds.Dy())
+		return nil, "", NewError(CodeDecode, "invalid image dimensions: %dx%d", bounds.Dx(), bounds.Dy())
 	}
 
 	return img, format, nil
 }
 
-func processImage(img image.Image, opts Options) image.Image {
+func downscaleImage(img image.Image, maxProcessDimension int, resample string) image.Image {
 	bounds := img.Bounds()
 	originalWidth, originalHeight := bounds.Dx(), bounds.Dy()
-	fmt.Printf("Original image dimensions: %dx%d\n", originalWidth, originalHeight)
+	Logf(LevelDebug, "Original image dimensions: %dx%d", originalWidth, originalHeight)
 
-	const maxProcessDimension = 1024
+	if maxProcessDimension <= 0 {
+		return img
+	}
 	if originalWidth > maxProcessDimension || originalHeight > maxProcessDimension {
 		scale := float64(maxProcessDimension) / float64(max(originalWidth, originalHeight))
 		newWidth := int(float64(originalWidth) * scale)
@@ -135,217 +322,448 @@ func processImage(img image.Image, opts Options) image.Image {
 		if newHeight < 1 {
 			newHeight = 1
 		}
-		fmt.Printf("Resizing to: %dx%d (scale: %.2f)\n", newWidth, newHeight, scale)
-		img = imaging.Resize(img, newWidth, newHeight, imaging.Lanczos)
+		Logf(LevelDebug, "Resizing to: %dx%d (scale: %.2f)", newWidth, newHeight, scale)
+		if isDeepImage(img) {
+			return resizeNRGBA64(img, newWidth, newHeight, resampleFilter(resample))
+		}
+		img = imaging.Resize(img, newWidth, newHeight, resampleFilter(resample))
 	}
 
-	if opts.Brightness != 0 {
-		img = imaging.AdjustBrightness(img, opts.Brightness)
+	return img
+}
+
+func adjustImage(img image.Image, opts Options) image.Image {
+	img = transformImage(img, opts)
+	if opts.MedianRadius > 0 {
+		img = medianFilter(img, opts.MedianRadius)
+	}
+	if opts.Blur > 0 {
+		img = imaging.Blur(img, opts.Blur)
+	}
+	if opts.AutoContrast {
+		img = equalizeHistogram(img)
+	}
+	if opts.AutoLevels {
+		img = stretchLevels(img, opts.AutoLevelsClip)
+	}
+	if opts.ClaheClipLimit > 0 {
+		img = applyCLAHE(img, opts.ClaheClipLimit, opts.ClaheTiles)
+	}
+	if (isDeepImage(img) || opts.LinearLight) && (opts.Brightness != 0 || opts.Contrast != 0) {
+		img = adjustLevels16(img, opts.Brightness, opts.Contrast, opts.LinearLight)
+	} else {
+		if opts.Brightness != 0 {
+			img = imaging.AdjustBrightness(img, opts.Brightness)
+		}
+		if opts.Contrast != 0 {
+			img = imaging.AdjustContrast(img, opts.Contrast)
+		}
+	}
+	if opts.HueShift != 0 {
+		img = shiftHue(img, opts.HueShift)
 	}
-	if opts.Contrast != 0 {
-		img = imaging.AdjustContrast(img, opts.Contrast)
+	if len(opts.ToneCurve) > 0 {
+		img = applyToneCurve(img, opts.ToneCurve)
 	}
-	if opts.Sharpen != 0 {
+	if opts.UnsharpAmount > 0 {
+		img = unsharpMask(img, opts.UnsharpRadius, opts.UnsharpAmount, opts.UnsharpThreshold)
+	} else if opts.Sharpen != 0 {
 		img = imaging.Sharpen(img, opts.Sharpen)
 	}
+	if opts.Pixelate > 1 {
+		pixelated := pixelate(img, opts.Pixelate)
+		defer releaseNRGBA(pixelated)
+		img = pixelated
+	}
+	if opts.ChromaKey != "" {
+		img = chromaKey(img, hexToRGB(opts.ChromaKey), opts.ChromaKeyTolerance)
+	}
 
-	return handleTransparency(img, opts.TransparencyColor, opts.TransparencyThreshold)
+	return handleTransparency(img, opts.TransparencyColor, opts.TransparencyThreshold, opts.LinearLight)
 }
 
-func convertToASCII(img image.Image, targetWidth int) (string, error) {
-	options := convert.DefaultOptions
-	options.FixedWidth = targetWidth
-	options.Colored = true
-	options.StretchedScreen = false
-
+func convertToASCII(img image.Image, opts Options, limits Limits) ([]*ansi.StyledText, int, int, error) {
 	bounds := img.Bounds()
 	aspectRatio := float64(bounds.Dy()) / float64(bounds.Dx())
-	options.FixedHeight = int(float64(targetWidth) * aspectRatio)
-	if options.FixedHeight < 1 {
-		options.FixedHeight = 1
-	}
 
-	if options.FixedWidth > MaxASCIIDimension {
-		scale := float64(MaxASCIIDimension) / float64(options.FixedWidth)
-		options.FixedWidth = MaxASCIIDimension
-		options.FixedHeight = int(float64(options.FixedHeight) * scale)
-	}
-	if options.FixedHeight > MaxASCIIDimension {
-		scale := float64(MaxASCIIDimension) / float64(options.FixedHeight)
-		options.FixedHeight = MaxASCIIDimension
-		options.FixedWidth = int(float64(options.FixedWidth) * scale)
+	img, fitWidth, fitHeight := fitToGrid(img, opts)
+	width, height := clampGrid(fitWidth, fitHeight, limits)
+	if width != fitWidth || height != fitHeight {
+		opts.warn(WarnClamped, "targetWidth", "ASCII grid was clamped from %dx%d to %dx%d (max dimension: %d)", fitWidth, fitHeight, width, height, limits.MaxASCIIDimension)
 	}
 
-	fmt.Printf("Original: %dx%d, ASCII: %dx%d, Ratio: %.2f\n",
-		bounds.Dx(), bounds.Dy(), options.FixedWidth, options.FixedHeight, aspectRatio)
+	Logf(LevelDebug, "Original: %dx%d, ASCII: %dx%d, Ratio: %.2f",
+		bounds.Dx(), bounds.Dy(), width, height, aspectRatio)
 
-	converter := convert.NewImageConverter()
-	asciiString := converter.Image2ASCIIString(img, &options)
-	if asciiString == "" {
-		return "", fmt.Errorf("failed to convert image to ASCII")
+	chars := width * height
+	if chars > limits.MaxASCIIChars {
+		return nil, 0, 0, newLimitError(chars, limits.MaxASCIIChars, "ASCII output is too large: %s characters (max: %s)",
+			formatNumber(chars), formatNumber(limits.MaxASCIIChars))
 	}
-
-	if len(asciiString) > MaxASCIIChars {
-		return "", fmt.Errorf("ASCII output is too large: %s characters (max: %s)",
-			formatNumber(len(asciiString)), formatNumber(MaxASCIIChars))
+	if chars > largeOutputChars {
+		opts.warn(WarnLargeOutput, "", "large ASCII output: %s characters", formatNumber(chars))
 	}
-	if len(asciiString) > 3_000_000 {
-		js.Global().Get("console").Call("warn",
-			fmt.Sprintf("Very large ASCII output: %s characters. Processing may take time.", formatNumber(len(asciiString))))
-	} else if len(asciiString) > 1_000_000 {
-		js.Global().Get("console").Call("log",
-			fmt.Sprintf("Large ASCII output: %s characters.", formatNumber(len(asciiString))))
-	}
-
-	return asciiString, nil
-}
 
-func parseANSI(asciiString string) ([]*ansi.StyledText, error) {
-	if asciiString == "" {
-		return nil, fmt.Errorf("ASCII string is empty")
+	converter, err := opts.resolveConverter()
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	if opts.Grain > 0 {
+		grained := applyGrain(img, width, height, opts.Grain, opts.GrainSeed)
+		defer releaseNRGBA(grained)
+		img = grained
 	}
-	styledText, err := ansi.Parse(asciiString)
+	grid, err := converter.Convert(img, width, height, opts)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse ANSI string: %w", err)
+		return nil, 0, 0, err
 	}
-	if styledText == nil {
-		return nil, fmt.Errorf("styled text is nil after parsing")
+	if len(grid) == 0 {
+		return nil, 0, 0, NewError(CodeConvert, "failed to convert image to ASCII")
 	}
-
-	const maxStyledElements = 100_000
-	if len(styledText) > maxStyledElements {
-		return nil, fmt.Errorf("too many styled text elements: %d (max: %d)", len(styledText), maxStyledElements)
+	if opts.CharSelector != nil {
+		if err := selectChars(grid, img, opts); err != nil {
+			return nil, 0, 0, err
+		}
 	}
-	if len(styledText) > 30_000 {
-		js.Global().Get("console").Call("warn",
-			fmt.Sprintf("Large number of styled text elements: %d. Processing may be slower.", len(styledText)))
+	if opts.ColorMergeTolerance > 0 {
+		mergeColorRuns(grid, opts.ColorMergeTolerance)
+	}
+	if opts.gridFilter != nil {
+		opts.gridFilter(grid)
 	}
 
-	return styledText, nil
+	styledText, err := gridToStyledText(grid, styledElementLimit(limits))
+	return styledText, width, height, err
 }
 
-const (
-	lineHeight    = 16
-	charWidth     = 16
-	fontSize      = 16
-	paddingTop    = -2
-	paddingBottom = 2
-	paddingLeft   = 1
-	paddingRight  = -6
-)
-
-func renderToSVG(styledText []*ansi.StyledText, backgroundColor string) (string, error) {
-	if styledText == nil {
-		return "", fmt.Errorf("styledText is nil")
-	}
-
+func renderToSVG(styledText []*ansi.StyledText, opts Options) (string, error) {
 	buffer := bufferPool.Get().(*bytes.Buffer)
 	buffer.Reset()
 	defer bufferPool.Put(buffer)
 
-	canvas := svg.New(buffer)
+	if err := writeSVG(buffer, styledText, opts); err != nil {
+		return "", err
+	}
+	return buffer.String(), nil
+}
+
+func writeSVG(w io.Writer, styledText []*ansi.StyledText, opts Options) error {
+	if styledText == nil {
+		return NewError(CodeRender, "styledText is nil")
+	}
+
+	var rows *lineWriter
+	if opts.OnLine != nil {
+		rows = newLineWriter(w, opts.OnLine)
+		w = rows
+	}
+	canvas := svg.New(w)
 	lines := splitStyledTextByLine(styledText)
-	svgWidth, svgHeight := calculateSVGDimensions(lines)
+	metrics := opts.cellMetrics()
+	svgWidth, svgHeight := calculateSVGDimensions(lines, metrics)
+	inset := borderInset(opts)
+	svgWidth, svgHeight = svgWidth+2*inset, svgHeight+2*inset
+	frameHeight := svgHeight
+	svgHeight += captionExtraHeight(opts, metrics)
 
-	canvas.Start(svgWidth, svgHeight)
-	canvas.Rect(0, 0, svgWidth, svgHeight, fmt.Sprintf("fill:%s", backgroundColor))
+	classes := collectColorClasses(lines, fallbackTextColor(opts))
 
-	yPos := paddingTop
-	for _, line := range lines {
-		renderLine(canvas, line, yPos, paddingLeft)
-		yPos += lineHeight
+	canvas.Start(svgWidth, svgHeight, svgRootAttributes(opts)...)
+	if opts.Title != "" {
+		canvas.Title(opts.Title)
+	}
+	if opts.Description != "" {
+		canvas.Desc(opts.Description)
+	}
+	fontFamily := fontFamilyCSS(opts.FontFamily)
+	fontFace := ""
+	if len(opts.EmbedFont) > 0 {
+		fontFamily = "'" + embeddedFontFamily + "'," + fontFamily
+		fontFace = fontFaceCSS(opts.EmbedFont)
+	}
+	canvas.Style("text/css", fontFace+classes.css(fontFamily, metrics.fontSize, textOutlineCSS(opts)))
+	writeBackground(canvas, svgWidth, svgHeight, opts)
+	for _, r := range borderRects(opts, svgWidth, frameHeight) {
+		canvas.Rect(int(r[0]), int(r[1]), int(r[2]), int(r[3]), fmt.Sprintf("fill:%s", borderColor(opts)))
+	}
+	if inset > 0 {
+		canvas.Gtransform(fmt.Sprintf("translate(%d,%d)", inset, inset))
 	}
 
+	if rows != nil {
+		shadow := writeTextShadowFilter(canvas, opts)
+		if err := rows.begin(); err != nil {
+			return err
+		}
+		yPos := metrics.paddingTop
+		for _, line := range lines {
+			renderBackgroundRuns(canvas, line, yPos, metrics, classes)
+			if shadow {
+				canvas.Group(fmt.Sprintf(`filter="url(#%s)"`, textShadowID))
+			}
+			renderTextRow(canvas, line, yPos, metrics, classes)
+			if shadow {
+				canvas.Gend()
+			}
+			yPos += metrics.lineHeight
+			if err := rows.endLine(); err != nil {
+				return err
+			}
+		}
+	} else {
+		yPos := metrics.paddingTop
+		for _, line := range lines {
+			renderBackgroundRuns(canvas, line, yPos, metrics, classes)
+			yPos += metrics.lineHeight
+		}
+
+		shadow := writeTextShadowFilter(canvas, opts)
+		if shadow {
+			canvas.Group(fmt.Sprintf(`filter="url(#%s)"`, textShadowID))
+		}
+		yPos = metrics.paddingTop
+		for _, line := range lines {
+			renderTextRow(canvas, line, yPos, metrics, classes)
+			yPos += metrics.lineHeight
+		}
+		if shadow {
+			canvas.Gend()
+		}
+	}
+
+	if inset > 0 {
+		canvas.Gend()
+	}
+	if opts.Caption != "" {
+		left, top := captionOrigin(opts, metrics, svgWidth, svgHeight)
+		canvas.Text(left, top+metrics.paddingTop, opts.Caption,
+			fmt.Sprintf("fill:%s;fill-opacity:%g", captionColor(opts), opts.CaptionOpacity),
+			fmt.Sprintf(`textLength="%d"`, stringWidth(opts.Caption)*metrics.charWidth))
+	}
 	canvas.End()
-	return buffer.String(), nil
+	if rows != nil {
+		return rows.finish()
+	}
+	return nil
 }
 
-func calculateSVGDimensions(lines [][]*ansi.StyledText) (width, height int) {
+func svgRootAttributes(opts Options) []string {
+	attrs := []string{`xml:space="preserve"`, `role="img"`}
+	if label := cmp.Or(opts.Title, opts.Description); label != "" {
+		var escaped strings.Builder
+		xml.EscapeText(&escaped, []byte(label))
+		attrs = append(attrs, fmt.Sprintf(`aria-label="%s"`, escaped.String()))
+	}
+	return attrs
+}
+
+func calculateSVGDimensions(lines [][]*ansi.StyledText, m cellMetrics) (width, height int) {
 	maxLineLength := 0
 	for _, line := range lines {
-		currentLineLength := 0
-		for _, styledChar := range line {
-			currentLineLength += len(styledChar.Label)
-		}
+		currentLineLength := lineWidth(line)
 		if currentLineLength > maxLineLength {
 			maxLineLength = currentLineLength
 		}
 	}
 
-	width = (maxLineLength * charWidth) + paddingLeft + paddingRight
-	height = (len(lines) * lineHeight) + paddingTop + paddingBottom
+	width = (maxLineLength * m.charWidth) + m.paddingLeft + m.paddingRight
+	height = (len(lines) * m.lineHeight) + m.paddingTop + m.paddingBottom
 
 	if width <= 0 {
-		width = charWidth + paddingLeft + paddingRight
+		width = m.charWidth + m.paddingLeft + m.paddingRight
 	}
 	if height <= 0 {
-		height = lineHeight + paddingTop + paddingBottom
+		height = m.lineHeight + m.paddingTop + m.paddingBottom
 	}
 
-	fmt.Printf("SVG dimensions: %dx%d (based on %d lines, max length: %d)\n", width, height, len(lines), maxLineLength)
+	Logf(LevelDebug, "SVG dimensions: %dx%d (based on %d lines, max length: %d)", width, height, len(lines), maxLineLength)
 	return width, height
 }
 
-func renderLine(canvas *svg.SVG, line []*ansi.StyledText, yPos, startX int) {
-	currentX := startX
+func renderBackgroundRuns(canvas *svg.SVG, line []*ansi.StyledText, yPos int, m cellMetrics, classes *colorClasses) {
+	runX, runWidth, runColor := m.paddingLeft, 0, ""
+	flush := func() {
+		if runColor != "" && runWidth > 0 {
+			canvas.Rect(runX-m.paddingLeft, yPos-m.paddingTop, runWidth, m.lineHeight, fmt.Sprintf(`class="%s"`, classes.class(runColor)))
+		}
+	}
+
+	currentX := m.paddingLeft
 	for _, styledChar := range line {
-		if styledChar.Label == "" {
+		labelWidth := stringWidth(styledChar.Label) * m.charWidth
+		if labelWidth == 0 {
 			continue
 		}
 
-		if styledChar.Label == " " {
-			currentX += charWidth
-			continue
+		bg := ""
+		if styledChar.BgCol != nil {
+			bg = styledChar.BgCol.Hex
 		}
+		if bg != runColor {
+			flush()
+			runX, runWidth, runColor = currentX, 0, bg
+		}
+		runWidth += labelWidth
+		currentX += labelWidth
+	}
+	flush()
+}
 
-		textColor := "#FFFFFF"
-		if styledChar.FgCol != nil && styledChar.FgCol.Hex != "" {
-			textColor = styledChar.FgCol.Hex
+func renderTextRow(canvas *svg.SVG, line []*ansi.StyledText, yPos int, m cellMetrics, classes *colorClasses) {
+	if m.dots != nil {
+		renderDots(canvas, line, yPos, m, classes)
+		line = withoutDots(line, m.dots)
+	}
+	renderLine(canvas, line, yPos, m, classes)
+}
+
+func renderLine(canvas *svg.SVG, line []*ansi.StyledText, yPos int, m cellMetrics, classes *colorClasses) {
+	startX := m.paddingLeft
+	var run strings.Builder
+	runX, runChars, runColumns, runClass, runStyle, runWide := startX, 0, 0, "", "", false
+	gapChars, started := 0, false
+	flush := func() {
+		if runChars == 0 {
+			return
 		}
+		if !started {
+			canvas.Textspan(startX, yPos, "")
+			started = true
+		} else if gapChars > 0 {
+			canvas.Span(strings.Repeat(" ", gapChars))
+		}
+		gapChars = 0
 
-		style := fmt.Sprintf("fill:%s; font-family:monospace; font-size:%dpx; dominant-baseline:text-before-edge", textColor, fontSize)
-		canvas.Text(currentX, yPos, styledChar.Label, style)
-		currentX += len(styledChar.Label) * charWidth
+		attrs := []string{fmt.Sprintf(`x="%d"`, runX), fmt.Sprintf(`class="%s"`, runClass)}
+		if runStyle != "" {
+			attrs = append(attrs, runStyle)
+		}
+		if runChars > 1 {
+			attrs = append(attrs, fmt.Sprintf(`textLength="%d"`, runColumns*m.charWidth))
+		}
+		canvas.Span(run.String(), attrs...)
+		run.Reset()
+		runChars, runColumns = 0, 0
+	}
+
+	currentX := startX
+	for _, styledChar := range line {
+		for _, segment := range widthSegments(styledChar.Label) {
+			if strings.Trim(segment.text, " ") == "" || styledChar.Invisible() {
+				flush()
+				if started {
+					gapChars += segment.columns
+				}
+				currentX += segment.columns * m.charWidth
+				continue
+			}
+
+			class := classes.textClass(styledChar)
+			style := textStyleCSS(styledChar)
+			if class != runClass || style != runStyle || segment.wide != runWide {
+				flush()
+				runX, runClass, runStyle, runWide = currentX, class, style, segment.wide
+			}
+			run.WriteString(segment.text)
+			runChars += utf8.RuneCountInString(segment.text)
+			runColumns += segment.columns
+			currentX += segment.columns * m.charWidth
+		}
+	}
+	flush()
+	if started {
+		canvas.TextEnd()
 	}
 }
 
-func handleTransparency(img image.Image, transparencyColorStr string, threshold float64) image.Image {
+func textStyleCSS(styledText *ansi.StyledText) string {
+	var styles []string
+	if styledText.Bold() {
+		styles = append(styles, "font-weight:bold")
+	}
+	if styledText.Italic() {
+		styles = append(styles, "font-style:italic")
+	}
+	if styledText.Faint() {
+		styles = append(styles, "opacity:0.5")
+	}
+	switch {
+	case styledText.Underlined() && styledText.Strikethrough():
+		styles = append(styles, "text-decoration:underline line-through")
+	case styledText.Underlined():
+		styles = append(styles, "text-decoration:underline")
+	case styledText.Strikethrough():
+		styles = append(styles, "text-decoration:line-through")
+	}
+	return strings.Join(styles, "; ")
+}
+
+func handleTransparency(img image.Image, transparencyColorStr string, threshold float64, linear bool) image.Image {
 	tColor := parseHexColor(transparencyColorStr)
 	if tColor == nil {
 		tColor = color.White
 	}
+	tr, tg, tb, _ := tColor.RGBA()
+	background := [3]float64{float64(tr) / 65535, float64(tg) / 65535, float64(tb) / 65535}
 
-	bounds := img.Bounds()
-	result := image.NewRGBA(bounds)
-	alphaThreshold := uint32(math.Floor(threshold * 65535))
-
-	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
-		for x := bounds.Min.X; x < bounds.Max.X; x++ {
-			originalColor := img.At(x, y)
-			r, g, b, a := originalColor.RGBA()
-
-			if a < alphaThreshold {
-				result.Set(x, y, tColor)
-			} else if a < 0xFFFF {
-				alphaFactor := float64(a) / 65535.0
-				tr, tg, tb, _ := tColor.RGBA()
-
-				blendR := (float64(r)/65535.0)*alphaFactor + (float64(tr)/65535.0)*(1-alphaFactor)
-				blendG := (float64(g)/65535.0)*alphaFactor + (float64(tg)/65535.0)*(1-alphaFactor)
-				blendB := (float64(b)/65535.0)*alphaFactor + (float64(tb)/65535.0)*(1-alphaFactor)
-
-				result.Set(x, y, color.RGBA{
-					R: uint8(blendR * 255),
-					G: uint8(blendG * 255),
-					B: uint8(blendB * 255),
-					A: 255,
-				})
-			} else {
-				result.Set(x, y, originalColor)
+	if isDeepImage(img) {
+		return handleTransparency16(toNRGBA64(img), background, threshold, linear)
+	}
+
+	dst := cloneNRGBA(img)
+	alphaThreshold := int(math.Floor(threshold * 65535))
+	width := dst.Rect.Dx()
+	parallelRows(0, dst.Rect.Dy(), func(y0, y1 int) {
+		for y := y0; y < y1; y++ {
+			row := dst.Pix[y*dst.Stride : y*dst.Stride+width*4]
+			for i := 0; i < len(row); i += 4 {
+				a := int(row[i+3])
+				if a == 0xFF {
+					continue
+				}
+				alpha := float64(a) / 255
+				if a*0x101 < alphaThreshold {
+					alpha = 0
+				}
+				for c := 0; c < 3; c++ {
+					row[i+c] = uint8(uint16(math.Round(blendChannel(float64(row[i+c])/255, background[c], alpha, linear)*65535)) >> 8)
+				}
+				row[i+3] = 0xFF
 			}
 		}
-	}
-	return result
+	})
+	return dst
+}
+
+func handleTransparency16(src *image.NRGBA64, background [3]float64, threshold float64, linear bool) image.Image {
+	bounds := src.Bounds()
+	dst := image.NewNRGBA64(bounds)
+	alphaThreshold := int(math.Floor(threshold * 65535))
+	width := bounds.Dx()
+	parallelRows(0, bounds.Dy(), func(y0, y1 int) {
+		for y := y0; y < y1; y++ {
+			srcRow := src.Pix[y*src.Stride : y*src.Stride+width*8]
+			dstRow := dst.Pix[y*dst.Stride : y*dst.Stride+width*8]
+			for i := 0; i < len(srcRow); i += 8 {
+				a := int(srcRow[i+6])<<8 | int(srcRow[i+7])
+				if a == 0xFFFF {
+					copy(dstRow[i:i+8], srcRow[i:i+8])
+					continue
+				}
+				alpha := float64(a) / 65535
+				if a < alphaThreshold {
+					alpha = 0
+				}
+				for c := 0; c < 3; c++ {
+					fg := float64(int(srcRow[i+c*2])<<8|int(srcRow[i+c*2+1])) / 65535
+					v := uint16(math.Round(blendChannel(fg, background[c], alpha, linear) * 65535))
+					dstRow[i+c*2], dstRow[i+c*2+1] = uint8(v>>8), uint8(v)
+				}
+				dstRow[i+6], dstRow[i+7] = 0xFF, 0xFF
+			}
+		}
+	})
+	return dst
 }
 
 func parseHexColor(hex string) color.Color {
@@ -369,6 +787,15 @@ func parseHexColor(hex string) color.Color {
 	return color.RGBA{R: uint8(r), G: uint8(g), B: uint8(b), A: 0xFF}
 }
 
+func hexToRGB(hex string) [3]uint8 {
+	c := parseHexColor(hex)
+	if c == nil {
+		return [3]uint8{0xFF, 0xFF, 0xFF}
+	}
+	r, g, b, _ := c.RGBA()
+	return [3]uint8{uint8(r >> 8), uint8(g >> 8), uint8(b >> 8)}
+}
+
 func splitStyledTextByLine(styledText []*ansi.StyledText) [][]*ansi.StyledText {
 	var lines [][]*ansi.StyledText
 	var currentLine []*ansi.StyledText
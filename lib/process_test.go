@@ -0,0 +1,90 @@
+package lib
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestMain(m *testing.M) {
+	SetLogLevel(LevelSilent)
+	os.Exit(m.Run())
+}
+
+func resultOutput(r *Result) []byte {
+	switch {
+	case r.Text != "":
+		return []byte(r.Text)
+	case r.Data != nil:
+		return r.Data
+	}
+	return []byte(r.SVG)
+}
+
+func TestProcessImageOutputFormats(t *testing.T) {
+	tests := []struct {
+		format string
+		prefix string
+	}{
+		{OutputSVG, "<?xml"},
+		{OutputSVGZ, "\x1f\x8b"},
+		{OutputPDF, "%PDF-"},
+		{OutputPNG, "\x89PNG\r\n\x1a\n"},
+		{OutputGIF, "GIF89a"},
+		{OutputANSI, "\x1b[38;2;"},
+		{OutputText, ""},
+		{OutputIRC, "\x03"},
+		{OutputANS, "\x1b["},
+		{OutputITerm2, "\x1b]1337;File="},
+		{OutputKitty, "\x1b_G"},
+	}
+	if len(tests) != len(OutputFormats) {
+		t.Fatalf("table covers %d formats, OutputFormats has %d", len(tests), len(OutputFormats))
+	}
+
+	image := readFixture(t, "video-001.png")
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			opts := DefaultOptions()
+			opts.OutputFormat = tt.format
+			opts.TargetWidth = 16
+
+			result, err := ProcessImage(image, opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.OutputFormat != tt.format {
+				t.Errorf("OutputFormat = %q, want %q", result.OutputFormat, tt.format)
+			}
+			if result.ASCIIWidth != 16 || result.ASCIIHeight <= 0 {
+				t.Errorf("grid = %dx%d, want 16 columns", result.ASCIIWidth, result.ASCIIHeight)
+			}
+			output := resultOutput(result)
+			if len(output) == 0 || !bytes.HasPrefix(output, []byte(tt.prefix)) {
+				t.Errorf("output starts with %q, want prefix %q", output[:min(len(output), 16)], tt.prefix)
+			}
+		})
+	}
+}
+
+func TestProcessImageModes(t *testing.T) {
+	image := readFixture(t, "video-001.png")
+	for _, mode := range ModeNames() {
+		t.Run(mode, func(t *testing.T) {
+			opts := DefaultOptions()
+			opts.Mode = mode
+			opts.OutputFormat = OutputText
+			opts.TargetWidth = 20
+
+			result, err := ProcessImage(image, opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			lines := strings.Split(strings.TrimSuffix(result.Text, "\n"), "\n")
+			if len(lines) != result.ASCIIHeight {
+				t.Errorf("got %d lines, want %d", len(lines), result.ASCIIHeight)
+			}
+		})
+	}
+}
@@ -0,0 +1,17 @@
+package lib
+
+const (
+	StageDecoding  = "decoding"
+	StageResizing  = "resizing"
+	StageASCII     = "ascii"
+	StageRendering = "rendering"
+	StageDone      = "done"
+)
+
+type ProgressFunc func(stage string, percent float64)
+
+func (o *Options) reportProgress(stage string, percent float64) {
+	if o.Progress != nil {
+		o.Progress(stage, percent)
+	}
+}
@@ -0,0 +1,77 @@
+package lib
+
+import (
+	"image"
+
+	"github.com/disintegration/imaging"
+)
+
+var quadrantChars = [16]rune{' ', '▘', '▝', '▀', '▖', '▌', '▞', '▛', '▗', '▚', '▐', '▜', '▄', '▙', '▟', '█'}
+
+func renderQuadrants(img image.Image, width, height int, opts Options) Grid {
+	resized := imaging.Resize(img, width*2, height*2, resampleFilter(opts.Resample))
+
+	grid := newGrid(width, height)
+	var block [4][3]uint8
+	for row := 0; row < height; row++ {
+		for col := 0; col < width; col++ {
+			for q := 0; q < 4; q++ {
+				i := (row*2+q/2)*resized.Stride + (col*2+q%2)*4
+				copy(block[q][:], resized.Pix[i:i+3])
+			}
+			mask, fg, bg := clusterQuadrant(block)
+			grid[row][col] = Cell{
+				Char: quadrantChars[mask],
+				FG:   rgbColor(fg[0], fg[1], fg[2]),
+				BG:   rgbColor(bg[0], bg[1], bg[2]),
+			}
+		}
+	}
+	return grid
+}
+
+func clusterQuadrant(block [4][3]uint8) (int, [3]uint8, [3]uint8) {
+	seedA, seedB, maxDist := 0, 0, -1
+	for i := 0; i < 4; i++ {
+		for j := i + 1; j < 4; j++ {
+			if d := colorDistance(block[i], block[j]); d > maxDist {
+				seedA, seedB, maxDist = i, j, d
+			}
+		}
+	}
+
+	mask := 0
+	var sumFg, sumBg [3]int
+	var countFg, countBg int
+	for q := 0; q < 4; q++ {
+		if colorDistance(block[q], block[seedA]) <= colorDistance(block[q], block[seedB]) {
+			mask |= 1 << q
+			for c := 0; c < 3; c++ {
+				sumFg[c] += int(block[q][c])
+			}
+			countFg++
+		} else {
+			for c := 0; c < 3; c++ {
+				sumBg[c] += int(block[q][c])
+			}
+			countBg++
+		}
+	}
+
+	var fg, bg [3]uint8
+	for c := 0; c < 3; c++ {
+		fg[c] = uint8(sumFg[c] / countFg)
+		bg[c] = fg[c]
+		if countBg > 0 {
+			bg[c] = uint8(sumBg[c] / countBg)
+		}
+	}
+	return mask, fg, bg
+}
+
+func colorDistance(a, b [3]uint8) int {
+	dr := int(a[0]) - int(b[0])
+	dg := int(a[1]) - int(b[1])
+	db := int(a[2]) - int(b[2])
+	return dr*dr + dg*dg + db*db
+}
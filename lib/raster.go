@@ -0,0 +1,172 @@
+package lib
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"math"
+
+	"github.com/leaanthony/go-ansi-parser"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+const (
+	defaultRasterScale = 1.0
+	maxRasterScale     = 4.0
+	maxRasterPixels    = 64 * 1024 * 1024
+)
+
+func renderToPNG(styledText []*ansi.StyledText, opts Options) ([]byte, error) {
+	img, err := rasterize(styledText, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var buffer bytes.Buffer
+	if err := png.Encode(&buffer, img); err != nil {
+		return nil, NewError(CodeRender, "failed to encode PNG: %w", err)
+	}
+	return buffer.Bytes(), nil
+}
+
+func rasterize(styledText []*ansi.StyledText, opts Options) (*image.NRGBA, error) {
+	if styledText == nil {
+		return nil, NewError(CodeRender, "styledText is nil")
+	}
+
+	lines := splitStyledTextByLine(styledText)
+	m := opts.cellMetrics()
+	width, height := calculateSVGDimensions(lines, m)
+	inset := borderInset(opts)
+	width, height = width+2*inset, height+2*inset
+	frameHeight := height
+	height += captionExtraHeight(opts, m)
+	scale := opts.RasterScale
+	pixelWidth := int(math.Ceil(float64(width) * scale))
+	pixelHeight := int(math.Ceil(float64(height) * scale))
+	if pixels := pixelWidth * pixelHeight; pixels > maxRasterPixels {
+		return nil, newLimitError(pixels, maxRasterPixels, "raster output is too large: %dx%d pixels", pixelWidth, pixelHeight)
+	}
+
+	background := hexToRGB(opts.BackgroundColor)
+	painter := &rasterPainter{
+		img:     image.NewNRGBA(image.Rect(0, 0, pixelWidth, pixelHeight)),
+		scale:   scale,
+		opacity: 1,
+		metrics: m,
+	}
+	painter.fillBackground(opts)
+	for _, r := range borderRects(opts, width, frameHeight) {
+		painter.fillRect(hexToRGB(borderColor(opts)), r[0], r[1], r[2], r[3])
+	}
+	painter.offset = float64(inset)
+	paintCells(painter, lines, m, background, fallbackTextColor(opts))
+
+	if opts.Caption != "" {
+		left, top := captionOrigin(opts, m, width, height)
+		painter.offset, painter.opacity = 0, opts.CaptionOpacity
+		for i, char := range []rune(opts.Caption) {
+			painter.drawText(char, hexToRGB(captionColor(opts)), float64(left+i*m.charWidth), float64(top+m.paddingTop))
+		}
+	}
+	return painter.img, nil
+}
+
+type rasterPainter struct {
+	img     *image.NRGBA
+	scale   float64
+	offset  float64
+	opacity float64
+	metrics cellMetrics
+}
+
+func (p *rasterPainter) fillBackground(opts Options) {
+	from := hexToRGB(opts.BackgroundColor)
+	if opts.BackgroundGradient == "" {
+		p.fillRect(from, 0, 0, float64(p.img.Rect.Dx())/p.scale, float64(p.img.Rect.Dy())/p.scale)
+		return
+	}
+
+	to := hexToRGB(opts.BackgroundGradient)
+	angle := opts.BackgroundGradientAngle * math.Pi / 180
+	dx, dy := math.Cos(angle), math.Sin(angle)
+	bounds := p.img.Rect
+	for y := 0; y < bounds.Dy(); y++ {
+		for x := 0; x < bounds.Dx(); x++ {
+			u := float64(x)/float64(bounds.Dx()) - 0.5
+			v := float64(y)/float64(bounds.Dy()) - 0.5
+			t := clampFloat((u*dx+v*dy)+0.5, 0, 1)
+			i := y*p.img.Stride + x*4
+			for c := 0; c < 3; c++ {
+				p.img.Pix[i+c] = clampUint8(float64(from[c]) + (float64(to[c])-float64(from[c]))*t)
+			}
+			p.img.Pix[i+3] = 0xFF
+		}
+	}
+}
+
+func (p *rasterPainter) fillRect(c [3]uint8, x, y, width, height float64) {
+	x, y = x+p.offset, y+p.offset
+	rect := image.Rect(
+		int(math.Round(x*p.scale)), int(math.Round(y*p.scale)),
+		int(math.Round((x+width)*p.scale)), int(math.Round((y+height)*p.scale)),
+	).Intersect(p.img.Rect)
+	for py := rect.Min.Y; py < rect.Max.Y; py++ {
+		row := p.img.Pix[py*p.img.Stride:]
+		for px := rect.Min.X; px < rect.Max.X; px++ {
+			row[px*4], row[px*4+1], row[px*4+2], row[px*4+3] = c[0], c[1], c[2], 0xFF
+		}
+	}
+}
+
+func (p *rasterPainter) fillCircle(c [3]uint8, centerX, centerY, radius float64) {
+	centerX, centerY = (centerX+p.offset)*p.scale, (centerY+p.offset)*p.scale
+	radius *= p.scale
+	rect := image.Rect(
+		int(math.Floor(centerX-radius)), int(math.Floor(centerY-radius)),
+		int(math.Ceil(centerX+radius)), int(math.Ceil(centerY+radius)),
+	).Intersect(p.img.Rect)
+	for py := rect.Min.Y; py < rect.Max.Y; py++ {
+		row := p.img.Pix[py*p.img.Stride:]
+		for px := rect.Min.X; px < rect.Max.X; px++ {
+			if math.Hypot(float64(px)+0.5-centerX, float64(py)+0.5-centerY) > radius {
+				continue
+			}
+			row[px*4], row[px*4+1], row[px*4+2], row[px*4+3] = c[0], c[1], c[2], 0xFF
+		}
+	}
+}
+
+func (p *rasterPainter) drawText(char rune, c [3]uint8, left, top float64) {
+	left, top = left+p.offset, top+p.offset
+	face := basicfont.Face7x13
+	_, mask, maskp, _, ok := face.Glyph(fixed.Point26_6{}, char)
+	if !ok {
+		return
+	}
+
+	glyphHeight := face.Ascent + face.Descent
+	rect := image.Rect(
+		int(math.Round(left*p.scale)), int(math.Round(top*p.scale)),
+		int(math.Round((left+float64(p.metrics.glyphWidth))*p.scale)), int(math.Round((top+float64(p.metrics.fontSize))*p.scale)),
+	)
+	for py := rect.Min.Y; py < rect.Max.Y; py++ {
+		gy := (py - rect.Min.Y) * glyphHeight / max(rect.Dy(), 1)
+		for px := rect.Min.X; px < rect.Max.X; px++ {
+			if !(image.Point{px, py}.In(p.img.Rect)) {
+				continue
+			}
+			gx := (px - rect.Min.X) * face.Width / max(rect.Dx(), 1)
+			_, _, _, alpha := mask.At(maskp.X+gx, maskp.Y+gy).RGBA()
+			if alpha == 0 {
+				continue
+			}
+			a := float64(alpha) / 0xFFFF * p.opacity
+			i := py*p.img.Stride + px*4
+			for ch := 0; ch < 3; ch++ {
+				p.img.Pix[i+ch] = clampUint8(float64(p.img.Pix[i+ch])*(1-a) + float64(c[ch])*a)
+			}
+		}
+	}
+}
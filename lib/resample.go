@@ -0,0 +1,28 @@
+package lib
+
+import "github.com/disintegration/imaging"
+
+const (
+	ResampleLanczos    = "lanczos"
+	ResampleCatmullRom = "catmull-rom"
+	ResampleBox        = "box"
+	ResampleNearest    = "nearest"
+)
+
+var resampleFilters = map[string]imaging.ResampleFilter{
+	ResampleLanczos:    imaging.Lanczos,
+	ResampleCatmullRom: imaging.CatmullRom,
+	ResampleBox:        imaging.Box,
+	ResampleNearest:    imaging.NearestNeighbor,
+}
+
+func ResampleNames() []string {
+	return []string{ResampleLanczos, ResampleCatmullRom, ResampleBox, ResampleNearest}
+}
+
+func resampleFilter(name string) imaging.ResampleFilter {
+	if filter, ok := resampleFilters[name]; ok {
+		return filter
+	}
+	return imaging.Lanczos
+}
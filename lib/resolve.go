@@ -0,0 +1,83 @@
+package lib
+
+import "math"
+
+const rasterBytesPerPixel = 0.09
+
+var outputBytesPerCell = map[string]float64{
+	OutputSVG:  28,
+	OutputSVGZ: 3,
+	OutputPDF:  6,
+	OutputANSI: 14,
+	OutputText: 1,
+	OutputIRC:  3,
+	OutputANS:  3,
+}
+
+type OptionsReport struct {
+	Options       Options
+	ASCIIWidth    int
+	ASCIIHeight   int
+	EstimatedSize int
+	Warnings      []Warning
+}
+
+func ResolveOptions(opts Options, srcWidth, srcHeight int) (*OptionsReport, error) {
+	if srcWidth < 0 || srcHeight < 0 {
+		return nil, NewError(CodeBadInput, "invalid image dimensions: %dx%d", srcWidth, srcHeight)
+	}
+	if err := validateOptions(opts); err != nil {
+		return nil, err
+	}
+	opts.setDefaults()
+
+	limits := opts.limits()
+	report := &OptionsReport{}
+	if srcWidth == 0 || srcHeight == 0 {
+		report.ASCIIWidth, _ = clampGrid(opts.TargetWidth, 0, limits)
+	} else {
+		if dim := opts.MaxProcessDimension; dim > 0 && max(srcWidth, srcHeight) > dim {
+			opts.warn(WarnDownscaled, "maxProcessDimension", "image will be downscaled to fit %dx%d before conversion", dim, dim)
+		}
+		srcWidth, srcHeight = rotatedSize(srcWidth, srcHeight, opts.Rotate)
+		width, height := gridSize(srcWidth, srcHeight, opts)
+		report.ASCIIWidth, report.ASCIIHeight = clampGrid(width, height, limits)
+		if report.ASCIIWidth != width || report.ASCIIHeight != height {
+			opts.warn(WarnClamped, "targetWidth", "ASCII grid will be clamped from %dx%d to %dx%d (max dimension: %d)", width, height, report.ASCIIWidth, report.ASCIIHeight, limits.MaxASCIIDimension)
+		}
+		if chars := report.ASCIIWidth * report.ASCIIHeight; chars > limits.MaxASCIIChars {
+			return nil, newLimitError(chars, limits.MaxASCIIChars, "ASCII output is too large: %s characters (max: %s)",
+				formatNumber(chars), formatNumber(limits.MaxASCIIChars))
+		} else if chars > largeOutputChars {
+			opts.warn(WarnLargeOutput, "", "large ASCII output: %s characters", formatNumber(chars))
+		}
+		report.EstimatedSize = estimateOutputSize(report.ASCIIWidth, report.ASCIIHeight, opts)
+	}
+
+	report.Warnings = opts.collectedWarnings()
+	opts.warnings = nil
+	report.Options = opts
+	return report, nil
+}
+
+func rotatedSize(width, height int, angle float64) (int, int) {
+	radians := angle * math.Pi / 180
+	sin, cos := math.Abs(math.Sin(radians)), math.Abs(math.Cos(radians))
+	return int(math.Ceil(float64(width)*cos + float64(height)*sin - 1e-9)),
+		int(math.Ceil(float64(width)*sin + float64(height)*cos - 1e-9))
+}
+
+func estimateOutputSize(width, height int, opts Options) int {
+	cells := float64(width * height)
+	switch opts.OutputFormat {
+	case OutputPNG, OutputGIF, OutputITerm2, OutputKitty:
+		m := opts.cellMetrics()
+		pixels := cells * float64(m.charWidth*m.lineHeight) * opts.RasterScale * opts.RasterScale
+		size := pixels * rasterBytesPerPixel
+		if opts.OutputFormat == OutputITerm2 || opts.OutputFormat == OutputKitty {
+			size = size * 4 / 3
+		}
+		return int(size)
+	}
+	return int(cells * outputBytesPerCell[opts.OutputFormat])
+}
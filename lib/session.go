@@ -0,0 +1,59 @@
+package lib
+
+import (
+	"image"
+	"sync"
+	"time"
+)
+
+type Session struct {
+	img    image.Image
+	format string
+
+	mu             sync.Mutex
+	scaled         image.Image
+	scaledDim      int
+	scaledResample string
+}
+
+func NewSession(imageData []byte) (*Session, error) {
+	if err := validateImageData(imageData); err != nil {
+		return nil, err
+	}
+	if err := checkImageMemory(imageData, DefaultOptions()); err != nil {
+		return nil, err
+	}
+
+	img, format, err := decodeImage(imageData)
+	if err != nil {
+		return nil, err
+	}
+	Logf(LevelDebug, "Session image decoded successfully. Format: %s", format)
+
+	return &Session{img: img, format: format}, nil
+}
+
+func (s *Session) Render(opts Options) (*Result, error) {
+	start := time.Now()
+	if err := validateOptions(opts); err != nil {
+		return nil, err
+	}
+	opts.setDefaults()
+	bounds := s.img.Bounds()
+	if err := checkMemoryBudget(estimateMemory(bounds.Dx(), bounds.Dy(), bytesPerPixel(s.img.ColorModel()), false, opts)); err != nil {
+		return nil, err
+	}
+
+	return renderImage(opts.noteDownscale(s.img, s.scaledImage(opts.MaxProcessDimension, opts.Resample)), s.format, start, opts)
+}
+
+func (s *Session) scaledImage(maxProcessDimension int, resample string) image.Image {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.scaled == nil || s.scaledDim != maxProcessDimension || s.scaledResample != resample {
+		s.scaled = downscaleImage(s.img, maxProcessDimension, resample)
+		s.scaledDim = maxProcessDimension
+		s.scaledResample = resample
+	}
+	return s.scaled
+}
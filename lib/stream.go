@@ -0,0 +1,99 @@
+package lib
+
+import (
+	"compress/gzip"
+
+	"github.com/leaanthony/go-ansi-parser"
+)
+
+const svgChunkSize = 64 * 1024
+
+type ChunkFunc func(chunk []byte) error
+
+type chunkWriter struct {
+	onChunk ChunkFunc
+	buf     []byte
+	size    int
+	err     error
+}
+
+func newChunkWriter(onChunk ChunkFunc, size int) *chunkWriter {
+	return &chunkWriter{
+		onChunk: onChunk,
+		buf:     make([]byte, 0, size),
+		size:    size,
+	}
+}
+
+func (w *chunkWriter) Write(p []byte) (int, error) {
+	if w.err != nil {
+		return 0, w.err
+	}
+
+	w.buf = append(w.buf, p...)
+	if len(w.buf) >= w.size {
+		w.emit()
+	}
+	if w.err != nil {
+		return 0, w.err
+	}
+	return len(p), nil
+}
+
+func (w *chunkWriter) Flush() error {
+	if w.err == nil && len(w.buf) > 0 {
+		w.emit()
+	}
+	return w.err
+}
+
+func (w *chunkWriter) emit() {
+	if err := w.onChunk(w.buf); err != nil {
+		w.err = NewError(CodeRender, "output chunk callback failed: %w", err)
+	}
+	w.buf = w.buf[:0]
+}
+
+func streamOutput(styledText []*ansi.StyledText, opts Options) error {
+	writer := newChunkWriter(opts.OnChunk, svgChunkSize)
+	switch opts.OutputFormat {
+	case OutputSVGZ:
+		zw := gzip.NewWriter(writer)
+		if err := writeSVG(zw, styledText, opts); err != nil {
+			return err
+		}
+		if err := zw.Close(); err != nil {
+			return NewError(CodeRender, "failed to compress SVG: %w", err)
+		}
+	case OutputPDF, OutputPNG, OutputGIF, OutputANS:
+		render := renderToPDF
+		switch opts.OutputFormat {
+		case OutputPNG:
+			render = renderToPNG
+		case OutputGIF:
+			render = renderToGIF
+		case OutputANS:
+			render = renderToANS
+		}
+		data, err := render(styledText, opts)
+		if err != nil {
+			return err
+		}
+		if _, err := writer.Write(data); err != nil {
+			return err
+		}
+	case OutputANSI, OutputText, OutputIRC:
+		if err := writeText(writer, styledText, opts); err != nil {
+			return err
+		}
+	case OutputITerm2, OutputKitty:
+		if err := writeInlineImage(writer, styledText, opts); err != nil {
+			return err
+		}
+	default:
+		if err := writeSVG(writer, styledText, opts); err != nil {
+			return err
+		}
+	}
+	return writer.Flush()
+}
@@ -0,0 +1,91 @@
+package lib
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/leaanthony/go-ansi-parser"
+)
+
+const (
+	monospaceFallbacks = "ui-monospace,SFMono-Regular,Menlo,Consolas,'Liberation Mono',monospace"
+)
+
+type colorClasses struct {
+	names        map[string]string
+	order        []string
+	fallbackText string
+}
+
+func collectColorClasses(lines [][]*ansi.StyledText, fallbackText string) *colorClasses {
+	classes := &colorClasses{names: make(map[string]string), fallbackText: fallbackText}
+	for _, line := range lines {
+		for _, styledChar := range line {
+			if styledChar.BgCol != nil && styledChar.BgCol.Hex != "" {
+				classes.add(styledChar.BgCol.Hex)
+			}
+			if strings.Trim(styledChar.Label, " ") != "" {
+				classes.add(textColorOf(styledChar, fallbackText))
+			}
+		}
+	}
+	return classes
+}
+
+func (c *colorClasses) add(hex string) {
+	if _, ok := c.names[hex]; !ok {
+		c.names[hex] = fmt.Sprintf("c%d", len(c.order))
+		c.order = append(c.order, hex)
+	}
+}
+
+func (c *colorClasses) class(hex string) string {
+	return c.names[hex]
+}
+
+func (c *colorClasses) textClass(styledText *ansi.StyledText) string {
+	return c.names[textColorOf(styledText, c.fallbackText)]
+}
+
+func (c *colorClasses) css(fontFamily string, fontSize int, textStyle string) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "text{font-family:%s;font-size:%dpx;dominant-baseline:text-before-edge%s}", fontFamily, fontSize, textStyle)
+	for _, hex := range c.order {
+		fmt.Fprintf(&sb, ".%s{fill:%s}", c.names[hex], hex)
+	}
+	return sb.String()
+}
+
+func textColorOf(styledText *ansi.StyledText, fallback string) string {
+	if styledText.FgCol != nil && styledText.FgCol.Hex != "" {
+		return styledText.FgCol.Hex
+	}
+	return fallback
+}
+
+func fallbackTextColor(opts Options) string {
+	if opts.DefaultTextColor != "" {
+		return opts.DefaultTextColor
+	}
+	bg := hexToRGB(opts.BackgroundColor)
+	luminance := (0.2126*float64(bg[0]) + 0.7152*float64(bg[1]) + 0.0722*float64(bg[2])) / 255
+	if luminance > 0.5 {
+		return "#000000"
+	}
+	return "#FFFFFF"
+}
+
+func fontFamilyCSS(family string) string {
+	var names []string
+	for _, name := range strings.Split(family, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if strings.Contains(name, " ") && !strings.HasPrefix(name, "'") && !strings.HasPrefix(name, `"`) {
+			name = "'" + name + "'"
+		}
+		names = append(names, name)
+	}
+	return strings.Join(append(names, monospaceFallbacks), ",")
+}
@@ -0,0 +1,20 @@
+package lib
+
+import (
+	"bytes"
+	"compress/gzip"
+
+	"github.com/leaanthony/go-ansi-parser"
+)
+
+func renderToSVGZ(styledText []*ansi.StyledText, opts Options) ([]byte, error) {
+	var buffer bytes.Buffer
+	zw := gzip.NewWriter(&buffer)
+	if err := writeSVG(zw, styledText, opts); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, NewError(CodeRender, "failed to compress SVG: %w", err)
+	}
+	return buffer.Bytes(), nil
+}
@@ -0,0 +1,118 @@
+package lib
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/leaanthony/go-ansi-parser"
+)
+
+func renderToText(styledText []*ansi.StyledText, opts Options) (string, error) {
+	var sb strings.Builder
+	if err := writeText(&sb, styledText, opts); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+func writeText(w io.Writer, styledText []*ansi.StyledText, opts Options) error {
+	if styledText == nil {
+		return NewError(CodeRender, "styledText is nil")
+	}
+
+	var lines *lineWriter
+	if opts.OnLine != nil {
+		lines = newLineWriter(w, opts.OnLine)
+		w = lines
+	}
+	bw := bufio.NewWriter(w)
+	irc := opts.OutputFormat == OutputIRC
+	colored := opts.OutputFormat == OutputANSI || irc
+	for _, line := range splitStyledTextByLine(styledText) {
+		var fg, bg, ircCode string
+		for _, styledChar := range line {
+			nextFG, nextBG := colorHex(styledChar.FgCol), colorHex(styledChar.BgCol)
+			switch {
+			case !colored:
+			case irc:
+				if code := ircColorCode(nextFG, nextBG); code != ircCode {
+					ircCode = code
+					bw.WriteString(code)
+				}
+			default:
+				if nextFG != fg {
+					bw.WriteString(ansiColorCode(38, nextFG))
+				}
+				if nextBG != bg {
+					bw.WriteString(ansiColorCode(48, nextBG))
+				}
+			}
+			fg, bg = nextFG, nextBG
+			bw.WriteString(styledChar.Label)
+		}
+		if colored && (fg != "" || bg != "") {
+			if irc {
+				bw.WriteString("\x0f")
+			} else {
+				bw.WriteString("\x1b[0m")
+			}
+		}
+		bw.WriteByte('\n')
+		if lines != nil {
+			if err := bw.Flush(); err != nil {
+				return NewError(CodeRender, "failed to write text output: %w", err)
+			}
+			if err := lines.endLine(); err != nil {
+				return err
+			}
+		}
+	}
+	if opts.Caption != "" {
+		fmt.Fprintln(bw, opts.Caption)
+	}
+
+	if err := bw.Flush(); err != nil {
+		return NewError(CodeRender, "failed to write text output: %w", err)
+	}
+	if lines != nil {
+		return lines.finish()
+	}
+	return nil
+}
+
+func colorHex(col *ansi.Col) string {
+	if col == nil {
+		return ""
+	}
+	return col.Hex
+}
+
+func ansiColorCode(layer int, hex string) string {
+	if hex == "" {
+		return fmt.Sprintf("\x1b[%dm", layer+1)
+	}
+	c := hexToRGB(hex)
+	return fmt.Sprintf("\x1b[%d;2;%d;%d;%dm", layer, c[0], c[1], c[2])
+}
+
+var ircPalette = [][3]uint8{
+	{0xFF, 0xFF, 0xFF}, {0x00, 0x00, 0x00}, {0x00, 0x00, 0x7F}, {0x00, 0x93, 0x00},
+	{0xFF, 0x00, 0x00}, {0x7F, 0x00, 0x00}, {0x9C, 0x00, 0x9C}, {0xFC, 0x7F, 0x00},
+	{0xFF, 0xFF, 0x00}, {0x00, 0xFC, 0x00}, {0x00, 0x93, 0x93}, {0x00, 0xFF, 0xFF},
+	{0x00, 0x00, 0xFC}, {0xFF, 0x00, 0xFF}, {0x7F, 0x7F, 0x7F}, {0xD2, 0xD2, 0xD2},
+}
+
+const ircDefaultColor = 99
+
+func ircColorCode(fg, bg string) string {
+	return fmt.Sprintf("\x03%02d,%02d", ircColorIndex(fg), ircColorIndex(bg))
+}
+
+func ircColorIndex(hex string) int {
+	if hex == "" {
+		return ircDefaultColor
+	}
+	return nearestPaletteIndex(ircPalette, hexToRGB(hex))
+}
@@ -0,0 +1,47 @@
+package lib
+
+const (
+	ThemeDark           = "dark"
+	ThemeLight          = "light"
+	ThemeSolarized      = "solarized"
+	ThemeSolarizedLight = "solarized-light"
+)
+
+type theme struct {
+	background string
+	text       string
+	invert     bool
+}
+
+var themes = map[string]theme{
+	ThemeDark:           {background: "#000000", text: "#FFFFFF"},
+	ThemeLight:          {background: "#FFFFFF", text: "#000000", invert: true},
+	ThemeSolarized:      {background: "#002B36", text: "#839496"},
+	ThemeSolarizedLight: {background: "#FDF6E3", text: "#657B83", invert: true},
+}
+
+func ThemeNames() []string {
+	return []string{ThemeDark, ThemeLight, ThemeSolarized, ThemeSolarizedLight}
+}
+
+func (o *Options) applyTheme() {
+	t, ok := themes[o.Theme]
+	if !ok {
+		return
+	}
+	if o.BackgroundColor == "" || o.BackgroundColor == "#000000" {
+		o.BackgroundColor = t.background
+	}
+	if o.TransparencyColor == "" || o.TransparencyColor == "#FFFFFF" {
+		o.TransparencyColor = t.background
+	}
+	if o.DefaultTextColor == "" {
+		o.DefaultTextColor = t.text
+	}
+	if o.MonochromeColor == "" || o.MonochromeColor == "#FFFFFF" {
+		o.MonochromeColor = t.text
+	}
+	if t.invert {
+		o.Invert = true
+	}
+}
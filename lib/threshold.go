@@ -0,0 +1,83 @@
+package lib
+
+import (
+	"image"
+
+	"github.com/disintegration/imaging"
+)
+
+const ModeThreshold = "threshold"
+
+func renderThreshold(img image.Image, width, height int, opts Options) Grid {
+	resized := imaging.Resize(img, width, height, resampleFilter(opts.Resample))
+	ramp := []rune(charsetPresets[opts.Charset])
+	off, on := ramp[0], ramp[len(ramp)-1]
+
+	values := make([]float64, width*height)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			i := y*resized.Stride + x*4
+			values[y*width+x] = intensityOf(resized.Pix[i:i+4], opts.LuminanceFormula)
+		}
+	}
+	if opts.Invert {
+		invertValues(values)
+	}
+
+	threshold := opts.Threshold
+	if threshold == 0 {
+		threshold = otsuThreshold(values)
+	}
+	for i := range values {
+		values[i] += 0.5 - threshold
+	}
+	applyDither(values, width, height, 2, opts.Dither)
+
+	mono := hexToRGB(opts.MonochromeColor)
+	grid := newGrid(width, height)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			cell := &grid[y][x]
+			cell.Char = off
+			if values[y*width+x] >= 0.5 {
+				cell.Char = on
+			}
+			cell.FG = rgbColor(mono[0], mono[1], mono[2])
+		}
+	}
+	return grid
+}
+
+func otsuThreshold(values []float64) float64 {
+	var histogram [256]int
+	for _, v := range values {
+		histogram[int(clampFloat(v, 0, 1)*255+0.5)]++
+	}
+
+	total := len(values)
+	sum := 0.0
+	for i, count := range histogram {
+		sum += float64(i * count)
+	}
+
+	best, bestVariance := 0, -1.0
+	sumBackground, weightBackground := 0.0, 0
+	for i, count := range histogram {
+		weightBackground += count
+		if weightBackground == 0 {
+			continue
+		}
+		weightForeground := total - weightBackground
+		if weightForeground == 0 {
+			break
+		}
+		sumBackground += float64(i * count)
+		meanBackground := sumBackground / float64(weightBackground)
+		meanForeground := (sum - sumBackground) / float64(weightForeground)
+		diff := meanBackground - meanForeground
+		if variance := float64(weightBackground) * float64(weightForeground) * diff * diff; variance > bestVariance {
+			best, bestVariance = i, variance
+		}
+	}
+	return (float64(best) + 0.5) / 255
+}
@@ -0,0 +1,60 @@
+package lib
+
+import "github.com/leaanthony/go-ansi-parser"
+
+func isEmptyCell(styledText *ansi.StyledText, char rune) bool {
+	if styledText.BgCol != nil && styledText.BgCol.Hex != "" {
+		return false
+	}
+	return char == ' ' || styledText.Invisible()
+}
+
+func trimWhitespace(styledText []*ansi.StyledText) ([]*ansi.StyledText, int, int, bool) {
+	lines := splitStyledTextByLine(styledText)
+	firstRow, lastRow := -1, -1
+	left, right := -1, 0
+	for row, line := range lines {
+		col := 0
+		for _, styledChar := range line {
+			for _, char := range styledChar.Label {
+				if !isEmptyCell(styledChar, char) {
+					if firstRow < 0 {
+						firstRow = row
+					}
+					lastRow = row
+					if left < 0 || col < left {
+						left = col
+					}
+					right = max(right, col+1)
+				}
+				col++
+			}
+		}
+	}
+	if firstRow < 0 {
+		return styledText, 0, 0, false
+	}
+
+	var result []*ansi.StyledText
+	for row := firstRow; row <= lastRow; row++ {
+		if row > firstRow {
+			result = append(result, &ansi.StyledText{Label: "\n"})
+		}
+		col := 0
+		for _, styledChar := range lines[row] {
+			runes := []rune(styledChar.Label)
+			start, end := max(left-col, 0), min(right-col, len(runes))
+			col += len(runes)
+			if start >= end {
+				continue
+			}
+			result = append(result, &ansi.StyledText{
+				Label: string(runes[start:end]),
+				FgCol: styledChar.FgCol,
+				BgCol: styledChar.BgCol,
+				Style: styledChar.Style,
+			})
+		}
+	}
+	return result, right - left, lastRow - firstRow + 1, true
+}
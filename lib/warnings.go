@@ -0,0 +1,81 @@
+package lib
+
+import (
+	"fmt"
+	"image"
+	"slices"
+)
+
+type WarningCode string
+
+const (
+	WarnDownscaled    WarningCode = "WARN_DOWNSCALED"
+	WarnClamped       WarningCode = "WARN_CLAMPED"
+	WarnLargeOutput   WarningCode = "WARN_LARGE_OUTPUT"
+	WarnIgnoredOption WarningCode = "WARN_IGNORED_OPTION"
+	WarnOutputWidth   WarningCode = "WARN_OUTPUT_WIDTH"
+)
+
+const (
+	largeOutputChars    = 1_000_000
+	largeStyledElements = 30_000
+)
+
+type Warning struct {
+	Code    WarningCode
+	Message string
+	Option  string
+}
+
+type warningList struct {
+	items []Warning
+}
+
+func (o *Options) warn(code WarningCode, option string, format string, args ...any) {
+	w := Warning{Code: code, Message: fmt.Sprintf(format, args...), Option: option}
+	Logf(LevelWarn, "%s", w.Message)
+	if o.warnings == nil || slices.Contains(o.warnings.items, w) {
+		return
+	}
+	o.warnings.items = append(o.warnings.items, w)
+}
+
+func (o *Options) collectedWarnings() []Warning {
+	if o.warnings == nil {
+		return nil
+	}
+	return slices.Clone(o.warnings.items)
+}
+
+func (o *Options) downscale(img image.Image) image.Image {
+	return o.noteDownscale(img, downscaleImage(img, o.MaxProcessDimension, o.Resample))
+}
+
+func (o *Options) noteDownscale(original, scaled image.Image) image.Image {
+	from, to := original.Bounds(), scaled.Bounds()
+	if from.Dx() != to.Dx() || from.Dy() != to.Dy() {
+		o.warn(WarnDownscaled, "maxProcessDimension", "image was downscaled from %dx%d to %dx%d before conversion", from.Dx(), from.Dy(), to.Dx(), to.Dy())
+	}
+	return scaled
+}
+
+func (o *Options) warnIgnoredOptions() {
+	if o.Threshold != 0 && o.Mode != ModeThreshold {
+		o.warn(WarnIgnoredOption, "threshold", "threshold is ignored in %s mode", o.Mode)
+	}
+	if o.Charset != defaultCharset && slices.Contains([]string{ModeBraille, ModeHalfBlock, ModeQuadrant, ModeEmoji, ModeMosaic}, o.Mode) {
+		o.warn(WarnIgnoredOption, "charset", "charset is ignored in %s mode", o.Mode)
+	}
+	if o.FrameDelay != defaultFrameDelay && o.OutputFormat != OutputGIF {
+		o.warn(WarnIgnoredOption, "frameDelay", "frameDelay is ignored for %s output", o.OutputFormat)
+	}
+	if o.RasterScale != defaultRasterScale && !slices.Contains([]string{OutputPNG, OutputGIF, OutputITerm2, OutputKitty}, o.OutputFormat) {
+		o.warn(WarnIgnoredOption, "rasterScale", "rasterScale is ignored for %s output", o.OutputFormat)
+	}
+	if (o.LetterSpacing != 1 || o.LineSpacing != 1) && slices.Contains([]string{OutputANSI, OutputText, OutputIRC, OutputANS}, o.OutputFormat) {
+		o.warn(WarnIgnoredOption, "letterSpacing", "letter and line spacing are ignored for %s output", o.OutputFormat)
+	}
+	if (o.Sauce || o.Author != "") && o.OutputFormat != OutputANS {
+		o.warn(WarnIgnoredOption, "sauce", "SAUCE metadata is ignored for %s output", o.OutputFormat)
+	}
+}
@@ -0,0 +1,140 @@
+package lib
+
+import (
+	"unicode/utf8"
+
+	"github.com/leaanthony/go-ansi-parser"
+)
+
+var wideRanges = [][2]rune{
+	{0x1100, 0x115F},
+	{0x231A, 0x231B},
+	{0x23E9, 0x23EC},
+	{0x25FD, 0x25FE},
+	{0x2614, 0x2615},
+	{0x2648, 0x2653},
+	{0x26AA, 0x26AB},
+	{0x26BD, 0x26BE},
+	{0x26C4, 0x26C5},
+	{0x26F2, 0x26F5},
+	{0x2705, 0x2705},
+	{0x270A, 0x270B},
+	{0x2753, 0x2755},
+	{0x2795, 0x2797},
+	{0x2B1B, 0x2B1C},
+	{0x2B50, 0x2B55},
+	{0x2E80, 0x303E},
+	{0x3041, 0x33FF},
+	{0x3400, 0x4DBF},
+	{0x4E00, 0x9FFF},
+	{0xA000, 0xA4CF},
+	{0xA960, 0xA97F},
+	{0xAC00, 0xD7A3},
+	{0xF900, 0xFAFF},
+	{0xFE10, 0xFE19},
+	{0xFE30, 0xFE6F},
+	{0xFF00, 0xFF60},
+	{0xFFE0, 0xFFE6},
+	{0x1F004, 0x1F004},
+	{0x1F0CF, 0x1F0CF},
+	{0x1F18E, 0x1F18E},
+	{0x1F191, 0x1F19A},
+	{0x1F200, 0x1F251},
+	{0x1F300, 0x1F320},
+	{0x1F32D, 0x1F335},
+	{0x1F337, 0x1F37C},
+	{0x1F37E, 0x1F393},
+	{0x1F3A0, 0x1F3CA},
+	{0x1F3CF, 0x1F3D3},
+	{0x1F3E0, 0x1F3F0},
+	{0x1F3F4, 0x1F3F4},
+	{0x1F3F8, 0x1F43E},
+	{0x1F440, 0x1F440},
+	{0x1F442, 0x1F4FC},
+	{0x1F4FF, 0x1F53D},
+	{0x1F54B, 0x1F54E},
+	{0x1F550, 0x1F567},
+	{0x1F57A, 0x1F57A},
+	{0x1F595, 0x1F596},
+	{0x1F5A4, 0x1F5A4},
+	{0x1F5FB, 0x1F64F},
+	{0x1F680, 0x1F6C5},
+	{0x1F6CC, 0x1F6CC},
+	{0x1F6D0, 0x1F6D2},
+	{0x1F6D5, 0x1F6D7},
+	{0x1F6EB, 0x1F6EC},
+	{0x1F6F4, 0x1F6FC},
+	{0x1F7E0, 0x1F7EB},
+	{0x1F90C, 0x1F93A},
+	{0x1F93C, 0x1F945},
+	{0x1F947, 0x1F9FF},
+	{0x1FA70, 0x1FAFF},
+	{0x20000, 0x2FFFD},
+	{0x30000, 0x3FFFD},
+}
+
+func runeWidth(r rune) int {
+	if r < 0x1100 {
+		return 1
+	}
+	lo, hi := 0, len(wideRanges)
+	for lo < hi {
+		mid := (lo + hi) / 2
+		switch {
+		case r < wideRanges[mid][0]:
+			hi = mid
+		case r > wideRanges[mid][1]:
+			lo = mid + 1
+		default:
+			return 2
+		}
+	}
+	return 1
+}
+
+func stringWidth(s string) int {
+	width := 0
+	for _, r := range s {
+		width += runeWidth(r)
+	}
+	return width
+}
+
+func lineWidth(line []*ansi.StyledText) int {
+	width := 0
+	for _, styledChar := range line {
+		width += stringWidth(styledChar.Label)
+	}
+	return width
+}
+
+type widthSegment struct {
+	text    string
+	columns int
+	wide    bool
+}
+
+func widthSegments(s string) []widthSegment {
+	var segments []widthSegment
+	start, wide := 0, false
+	for i, r := range s {
+		w := runeWidth(r) == 2
+		if i > start && w != wide {
+			segments = append(segments, newWidthSegment(s[start:i], wide))
+			start = i
+		}
+		wide = w
+	}
+	if start < len(s) {
+		segments = append(segments, newWidthSegment(s[start:], wide))
+	}
+	return segments
+}
+
+func newWidthSegment(text string, wide bool) widthSegment {
+	columns := utf8.RuneCountInString(text)
+	if wide {
+		columns *= 2
+	}
+	return widthSegment{text: text, columns: columns, wide: wide}
+}
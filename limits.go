@@ -0,0 +1,55 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"image-to-ascii-art/lib"
+	"syscall/js"
+)
+
+var limitFields = map[string]func(l *lib.Limits, v int){
+	"maxImageSize":      func(l *lib.Limits, v int) { l.MaxImageSize = v },
+	"maxImagePixels":    func(l *lib.Limits, v int) { l.MaxImagePixels = v },
+	"maxOutputSize":     func(l *lib.Limits, v int) { l.MaxOutputSize = v },
+	"maxASCIIChars":     func(l *lib.Limits, v int) { l.MaxASCIIChars = v },
+	"maxASCIIDimension": func(l *lib.Limits, v int) { l.MaxASCIIDimension = v },
+	"maxMemory":         func(l *lib.Limits, v int) { l.MaxMemory = v },
+}
+
+func configureLimitsHandler(args []js.Value) (any, error) {
+	if len(args) != 1 || args[0].Type() != js.TypeObject {
+		return nil, lib.NewError(lib.CodeBadInput, "expected a limits object as the only argument")
+	}
+
+	var requested lib.Limits
+	keys := js.Global().Get("Object").Call("keys", args[0])
+	for i := 0; i < keys.Length(); i++ {
+		key := keys.Index(i).String()
+		set, ok := limitFields[key]
+		if !ok {
+			return nil, lib.NewOptionError(key, "unknown limit %q", key)
+		}
+		value := args[0].Get(key)
+		if value.Type() != js.TypeNumber {
+			return nil, lib.NewOptionError(key, "limit %q must be a number, got %s", key, value.Type())
+		}
+		set(&requested, value.Int())
+	}
+
+	limits, err := lib.ConfigureLimits(requested)
+	if err != nil {
+		return nil, err
+	}
+	return limitsToJS(limits), nil
+}
+
+func limitsToJS(limits lib.Limits) map[string]any {
+	return map[string]any{
+		"maxImageSize":      limits.MaxImageSize,
+		"maxImagePixels":    limits.MaxImagePixels,
+		"maxOutputSize":     limits.MaxOutputSize,
+		"maxASCIIChars":     limits.MaxASCIIChars,
+		"maxASCIIDimension": limits.MaxASCIIDimension,
+		"maxMemory":         limits.MaxMemory,
+	}
+}
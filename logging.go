@@ -0,0 +1,34 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"image-to-ascii-art/lib"
+	"syscall/js"
+)
+
+func setLogLevelHandler(args []js.Value) (any, error) {
+	if len(args) != 1 || args[0].Type() != js.TypeString {
+		return nil, lib.NewError(lib.CodeBadInput, "expected a log level name as the only argument")
+	}
+
+	level, err := lib.ParseLogLevel(args[0].String())
+	if err != nil {
+		return nil, err
+	}
+	lib.SetLogLevel(level)
+	return level.String(), nil
+}
+
+func consoleLogger(level lib.LogLevel, message string) {
+	method := "log"
+	switch level {
+	case lib.LevelError:
+		method = "error"
+	case lib.LevelWarn:
+		method = "warn"
+	case lib.LevelDebug:
+		method = "debug"
+	}
+	js.Global().Get("console").Call(method, message)
+}
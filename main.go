@@ -1,3 +1,5 @@
+//go:build js && wasm
+
 package main
 
 import (
@@ -6,65 +8,96 @@ import (
 	"syscall/js"
 )
 
-func validateImageParams(args []js.Value) ([]byte, lib.Options, error) {
-	if len(args) != 8 {
-		return nil, lib.Options{}, fmt.Errorf("expected 8 arguments, but got %d", len(args))
-	}
+func processImage(imageDataGo []byte, opts lib.Options) (*lib.Result, error) {
+	logOptions(opts)
 
-	imageDataJS := args[0]
-	if imageDataJS.IsNull() || imageDataJS.IsUndefined() {
-		return nil, lib.Options{}, fmt.Errorf("imageData is null or undefined")
+	result, err := lib.ProcessImage(imageDataGo, opts)
+	if err != nil {
+		return nil, fmt.Errorf("error processing image: %w", err)
 	}
 
-	imageDataLength := imageDataJS.Get("length")
-	if imageDataLength.IsNull() || imageDataLength.IsUndefined() {
-		return nil, lib.Options{}, fmt.Errorf("imageData has no length property")
-	}
+	lib.Logf(lib.LevelInfo, "Image processed successfully")
+	return result, nil
+}
 
-	length := imageDataLength.Int()
-	if length <= 0 {
-		return nil, lib.Options{}, fmt.Errorf("imageData length is invalid: %d", length)
-	}
+func processPixels(pixels []byte, width, height int, opts lib.Options) (*lib.Result, error) {
+	logOptions(opts)
 
-	imageDataGo := make([]byte, length)
-	js.CopyBytesToGo(imageDataGo, imageDataJS)
-
-	opts := lib.Options{
-		TargetWidth:           args[1].Int(),
-		Brightness:            args[2].Float(),
-		Contrast:              args[3].Float(),
-		Sharpen:               args[4].Float(),
-		BackgroundColor:       args[5].String(),
-		TransparencyColor:     args[6].String(),
-		TransparencyThreshold: args[7].Float(),
+	result, err := lib.ProcessPixels(pixels, width, height, opts)
+	if err != nil {
+		return nil, fmt.Errorf("error processing image: %w", err)
 	}
 
-	return imageDataGo, opts, nil
+	lib.Logf(lib.LevelInfo, "Image processed successfully")
+	return result, nil
 }
 
-func processImage(imageDataGo []byte, opts lib.Options) (string, error) {
-	js.Global().Get("console").Call("log",
-		fmt.Sprintf("Processing image: width=%d, brightness=%.2f, contrast=%.2f, sharpen=%.2f, bg_color=%s, transparency_color=%s, threshold=%.2f",
-			opts.TargetWidth, opts.Brightness, opts.Contrast, opts.Sharpen, opts.BackgroundColor, opts.TransparencyColor, opts.TransparencyThreshold))
+func resultToJS(result *lib.Result, detailed bool) any {
+	output := any(result.SVG)
+	if result.Text != "" {
+		output = result.Text
+	}
+	if result.Data != nil {
+		output = bytesToJS(result.Data)
+	}
+	if !detailed {
+		return output
+	}
+	detail := map[string]any{
+		"svg":          result.SVG,
+		"text":         result.Text,
+		"asciiWidth":   result.ASCIIWidth,
+		"asciiHeight":  result.ASCIIHeight,
+		"charCount":    result.CharCount,
+		"changedCells": result.ChangedCells,
+		"elapsedMs":    float64(result.Elapsed.Microseconds()) / 1000,
+		"format":       result.Format,
+		"outputFormat": result.OutputFormat,
+		"warnings":     warningsToJS(result.Warnings),
+	}
+	if result.Data != nil {
+		detail["data"] = output
+	}
+	return detail
+}
 
-	svgString, err := lib.ProcessImageToSVG(imageDataGo, opts)
-	if err != nil {
-		return "", fmt.Errorf("error processing image: %w", err)
+func warningsToJS(warnings []lib.Warning) []any {
+	output := make([]any, len(warnings))
+	for i, w := range warnings {
+		warning := map[string]any{"code": string(w.Code), "message": w.Message}
+		if w.Option != "" {
+			warning["option"] = w.Option
+		}
+		output[i] = warning
 	}
+	return output
+}
 
-	js.Global().Get("console").Call("log", "Image processed successfully")
-	return svgString, nil
+func bytesToJS(data []byte) js.Value {
+	array := js.Global().Get("Uint8Array").New(len(data))
+	js.CopyBytesToJS(array, data)
+	return array
+}
+
+func logOptions(opts lib.Options) {
+	lib.Logf(lib.LevelDebug, "Processing image: width=%d, brightness=%.2f, contrast=%.2f, sharpen=%.2f, bg_color=%s, transparency_color=%s, threshold=%.2f",
+		opts.TargetWidth, opts.Brightness, opts.Contrast, opts.Sharpen, opts.BackgroundColor, opts.TransparencyColor, opts.TransparencyThreshold)
 }
 
 func rejectWithError(reject js.Value, err error) {
 	errorConstructor := js.Global().Get("Error")
 	errorMsg := fmt.Sprintf("Error: %v", err)
-	js.Global().Get("console").Call("error", errorMsg)
+	lib.Logf(lib.LevelError, "%s", errorMsg)
 	errorObject := errorConstructor.New(errorMsg)
+	for name, value := range lib.ErrorFields(err) {
+		if name != "message" {
+			errorObject.Set(name, value)
+		}
+	}
 	reject.Invoke(errorObject)
 }
 
-func wrapperFunc() js.Func {
+func promiseFunc(fn func(args []js.Value) (any, error)) js.Func {
 	return js.FuncOf(func(this js.Value, args []js.Value) any {
 		handler := js.FuncOf(func(this js.Value, pArgs []js.Value) any {
 			resolve := pArgs[0]
@@ -73,54 +106,123 @@ func wrapperFunc() js.Func {
 			go func() {
 				defer func() {
 					if r := recover(); r != nil {
-						errorMsg := fmt.Sprintf("Panic in Go WASM: %v", r)
-						rejectWithError(reject, fmt.Errorf(errorMsg))
+						rejectWithError(reject, lib.NewError(lib.CodeInternal, "Panic in Go WASM: %v", r))
 					}
 				}()
 
-				imageDataGo, opts, err := validateImageParams(args)
-				if err != nil {
-					rejectWithError(reject, err)
-					return
-				}
-
-				svgString, err := processImage(imageDataGo, opts)
+				result, err := fn(args)
 				if err != nil {
 					rejectWithError(reject, err)
 					return
 				}
 
-				resolve.Invoke(svgString)
+				resolve.Invoke(result)
 			}()
 
 			return nil
 		})
 
 		promiseConstructor := js.Global().Get("Promise")
+		defer handler.Release()
 		return promiseConstructor.New(handler)
 	})
 }
 
-func main() {
-	js.Global().Get("console").Call("log", "Go WebAssembly Module Loaded")
+func processImageHandler(args []js.Value) (any, error) {
+	imageDataGo, opts, err := validateImageParams(args)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := processImage(imageDataGo, opts.Options)
+	if err != nil {
+		return nil, err
+	}
+	return resultToJS(result, opts.detailed), nil
+}
+
+func processImageSourceHandler(args []js.Value) (any, error) {
+	pixels, width, height, opts, err := validateImageSourceParams(args)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := processPixels(pixels, width, height, opts.Options)
+	if err != nil {
+		return nil, err
+	}
+	return resultToJS(result, opts.detailed), nil
+}
 
-	js.Global().Set("processImageGo", wrapperFunc())
+var exports = make(map[string]js.Func)
 
-	js.Global().Set("processImageGoSync", js.FuncOf(func(this js.Value, args []js.Value) any {
+func main() {
+	lib.SetLogger(consoleLogger)
+	lib.Logf(lib.LevelInfo, "Go WebAssembly Module Loaded")
+
+	export("processImageGo", promiseFunc(queued(processImageHandler)))
+	export("processImageSourceGo", promiseFunc(queued(processImageSourceHandler)))
+	export("processImagePreviewsGo", promiseFunc(queued(processPreviewsHandler)))
+	export("diffImagesGo", promiseFunc(queued(diffImagesHandler)))
+	export("processCollageGo", promiseFunc(queued(processCollageHandler)))
+	export("processFramesGo", promiseFunc(queued(processFramesHandler)))
+	export("processAnimationGo", promiseFunc(queued(processAnimationHandler)))
+	export("createImageSessionGo", promiseFunc(queued(createSessionHandler)))
+	export("renderSessionGo", promiseFunc(queued(renderSessionHandler)))
+	export("releaseSessionGo", js.FuncOf(releaseSession))
+	export("allocInputBufferGo", js.FuncOf(allocInputBuffer))
+	export("releaseInputBufferGo", js.FuncOf(releaseInputBuffer))
+	export("getCapabilitiesGo", js.FuncOf(getCapabilities))
+	export("validateOptionsGo", promiseFunc(validateOptionsHandler))
+	export("extractPaletteGo", promiseFunc(queued(extractPaletteHandler)))
+	export("configureLimitsGo", promiseFunc(configureLimitsHandler))
+	export("setLogLevelGo", promiseFunc(setLogLevelHandler))
+	export("setMaxConcurrentJobsGo", promiseFunc(setMaxConcurrentJobsHandler))
+
+	export("processImageGoSync", js.FuncOf(func(this js.Value, args []js.Value) any {
 		imageDataGo, opts, err := validateImageParams(args)
 		if err != nil {
-			js.Global().Get("console").Call("error", fmt.Sprintf("Validation Error: %v", err))
+			lib.Logf(lib.LevelError, "Validation Error: %v", err)
 			return ""
 		}
 
-		svgString, err := processImage(imageDataGo, opts)
+		result, err := processImage(imageDataGo, opts.Options)
 		if err != nil {
-			js.Global().Get("console").Call("error", fmt.Sprintf("Processing Error: %v", err))
+			lib.Logf(lib.LevelError, "Processing Error: %v", err)
 			return ""
 		}
 
-		return svgString
+		return resultToJS(result, opts.detailed)
 	}))
 
-	<-make(chan struct{})
+	done := make(chan struct{})
+	export("disposeImageToAsciiGo", js.FuncOf(func(this js.Value, args []js.Value) any {
+		dispose()
+		close(done)
+		return nil
+	}))
+
+	<-done
+	lib.Logf(lib.LevelInfo, "Go WebAssembly Module Disposed")
+}
+
+func export(name string, fn js.Func) {
+	js.Global().Set(name, fn)
+	exports[name] = fn
+}
+
+func dispose() {
+	for name, fn := range exports {
+		js.Global().Delete(name)
+		fn.Release()
+	}
+	clear(exports)
+
+	buffersMu.Lock()
+	clear(buffers)
+	buffersMu.Unlock()
+
+	sessionsMu.Lock()
+	defer sessionsMu.Unlock()
+	clear(sessions)
 }
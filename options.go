@@ -0,0 +1,688 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"image-to-ascii-art/lib"
+	"math"
+	"sort"
+	"strings"
+	"syscall/js"
+)
+
+type requestOptions struct {
+	lib.Options
+	detailed bool
+}
+
+type optionField struct {
+	kind      js.Type
+	apply     func(opts *requestOptions, v js.Value)
+	value     func(opts lib.Options) any
+	min, max  float64
+	dimension bool
+	values    []string
+}
+
+func (f optionField) bounds(maxDimension int) (float64, float64) {
+	if f.dimension {
+		return f.min, float64(maxDimension)
+	}
+	return f.min, f.max
+}
+
+var optionFields = map[string]optionField{
+	"targetWidth": {
+		kind:      js.TypeNumber,
+		apply:     func(opts *requestOptions, v js.Value) { opts.TargetWidth = v.Int() },
+		value:     func(opts lib.Options) any { return opts.TargetWidth },
+		min:       1,
+		dimension: true,
+	},
+	"outputWidth": {
+		kind:  js.TypeNumber,
+		apply: func(opts *requestOptions, v js.Value) { opts.OutputWidth = v.Int() },
+		value: func(opts lib.Options) any { return opts.OutputWidth },
+	},
+	"targetHeight": {
+		kind:      js.TypeNumber,
+		apply:     func(opts *requestOptions, v js.Value) { opts.TargetHeight = v.Int() },
+		value:     func(opts lib.Options) any { return opts.TargetHeight },
+		min:       0,
+		dimension: true,
+	},
+	"maxASCIIDimension": {
+		kind:  js.TypeNumber,
+		apply: func(opts *requestOptions, v js.Value) { opts.MaxASCIIDimension = v.Int() },
+		value: func(opts lib.Options) any { return opts.MaxASCIIDimension },
+		min:   0,
+		max:   lib.MaxASCIIDimensionCeiling,
+	},
+	"fit": {
+		kind:   js.TypeString,
+		apply:  func(opts *requestOptions, v js.Value) { opts.Fit = v.String() },
+		value:  func(opts lib.Options) any { return opts.Fit },
+		values: lib.FitNames(),
+	},
+	"preset": {
+		kind:   js.TypeString,
+		apply:  func(opts *requestOptions, v js.Value) { opts.Preset = v.String() },
+		value:  func(opts lib.Options) any { return opts.Preset },
+		values: lib.PresetNames(),
+	},
+	"theme": {
+		kind:   js.TypeString,
+		apply:  func(opts *requestOptions, v js.Value) { opts.Theme = v.String() },
+		value:  func(opts lib.Options) any { return opts.Theme },
+		values: lib.ThemeNames(),
+	},
+	"maxProcessDimension": {
+		kind:  js.TypeNumber,
+		apply: func(opts *requestOptions, v js.Value) { opts.MaxProcessDimension = v.Int() },
+		value: func(opts lib.Options) any { return opts.MaxProcessDimension },
+	},
+	"resample": {
+		kind:   js.TypeString,
+		apply:  func(opts *requestOptions, v js.Value) { opts.Resample = v.String() },
+		value:  func(opts lib.Options) any { return opts.Resample },
+		values: lib.ResampleNames(),
+	},
+	"brightness": {
+		kind:  js.TypeNumber,
+		apply: func(opts *requestOptions, v js.Value) { opts.Brightness = v.Float() },
+		value: func(opts lib.Options) any { return opts.Brightness },
+		min:   -100,
+		max:   100,
+	},
+	"contrast": {
+		kind:  js.TypeNumber,
+		apply: func(opts *requestOptions, v js.Value) { opts.Contrast = v.Float() },
+		value: func(opts lib.Options) any { return opts.Contrast },
+		min:   -100,
+		max:   100,
+	},
+	"sharpen": {
+		kind:  js.TypeNumber,
+		apply: func(opts *requestOptions, v js.Value) { opts.Sharpen = v.Float() },
+		value: func(opts lib.Options) any { return opts.Sharpen },
+		min:   0,
+		max:   5,
+	},
+	"backgroundColor": {
+		kind:  js.TypeString,
+		apply: func(opts *requestOptions, v js.Value) { opts.BackgroundColor = v.String() },
+		value: func(opts lib.Options) any { return opts.BackgroundColor },
+	},
+	"transparencyColor": {
+		kind:  js.TypeString,
+		apply: func(opts *requestOptions, v js.Value) { opts.TransparencyColor = v.String() },
+		value: func(opts lib.Options) any { return opts.TransparencyColor },
+	},
+	"transparencyThreshold": {
+		kind:  js.TypeNumber,
+		apply: func(opts *requestOptions, v js.Value) { opts.TransparencyThreshold = v.Float() },
+		value: func(opts lib.Options) any { return opts.TransparencyThreshold },
+		min:   0,
+		max:   1,
+	},
+	"chromaKey": {
+		kind:  js.TypeString,
+		apply: func(opts *requestOptions, v js.Value) { opts.ChromaKey = v.String() },
+		value: func(opts lib.Options) any { return opts.ChromaKey },
+	},
+	"chromaKeyTolerance": {
+		kind:  js.TypeNumber,
+		apply: func(opts *requestOptions, v js.Value) { opts.ChromaKeyTolerance = v.Float() },
+		value: func(opts lib.Options) any { return opts.ChromaKeyTolerance },
+		min:   0,
+		max:   1,
+	},
+	"charset": {
+		kind:   js.TypeString,
+		apply:  func(opts *requestOptions, v js.Value) { opts.Charset = v.String() },
+		value:  func(opts lib.Options) any { return opts.Charset },
+		values: lib.CharsetNames(),
+	},
+	"mode": {
+		kind:   js.TypeString,
+		apply:  func(opts *requestOptions, v js.Value) { opts.Mode = v.String() },
+		value:  func(opts lib.Options) any { return opts.Mode },
+		values: lib.ModeNames(),
+	},
+	"dither": {
+		kind:   js.TypeString,
+		apply:  func(opts *requestOptions, v js.Value) { opts.Dither = v.String() },
+		value:  func(opts lib.Options) any { return opts.Dither },
+		values: lib.DitherNames(),
+	},
+	"threshold": {
+		kind:  js.TypeNumber,
+		apply: func(opts *requestOptions, v js.Value) { opts.Threshold = v.Float() },
+		value: func(opts lib.Options) any { return opts.Threshold },
+		min:   0,
+		max:   1,
+	},
+	"grayscale": {
+		kind:  js.TypeBoolean,
+		apply: func(opts *requestOptions, v js.Value) { opts.Grayscale = v.Bool() },
+		value: func(opts lib.Options) any { return opts.Grayscale },
+	},
+	"luminanceFormula": {
+		kind:   js.TypeString,
+		apply:  func(opts *requestOptions, v js.Value) { opts.LuminanceFormula = v.String() },
+		value:  func(opts lib.Options) any { return opts.LuminanceFormula },
+		values: lib.LuminanceFormulaNames(),
+	},
+	"monochromeColor": {
+		kind:  js.TypeString,
+		apply: func(opts *requestOptions, v js.Value) { opts.MonochromeColor = v.String() },
+		value: func(opts lib.Options) any { return opts.MonochromeColor },
+	},
+	"invert": {
+		kind:  js.TypeBoolean,
+		apply: func(opts *requestOptions, v js.Value) { opts.Invert = v.Bool() },
+		value: func(opts lib.Options) any { return opts.Invert },
+	},
+	"linearLight": {
+		kind:  js.TypeBoolean,
+		apply: func(opts *requestOptions, v js.Value) { opts.LinearLight = v.Bool() },
+		value: func(opts lib.Options) any { return opts.LinearLight },
+	},
+	"hueShift": {
+		kind:  js.TypeNumber,
+		apply: func(opts *requestOptions, v js.Value) { opts.HueShift = v.Float() },
+		value: func(opts lib.Options) any { return opts.HueShift },
+		min:   -360,
+		max:   360,
+	},
+	"grain": {
+		kind:  js.TypeNumber,
+		apply: func(opts *requestOptions, v js.Value) { opts.Grain = v.Float() },
+		value: func(opts lib.Options) any { return opts.Grain },
+		min:   0,
+		max:   1,
+	},
+	"grainSeed": {
+		kind:  js.TypeNumber,
+		apply: func(opts *requestOptions, v js.Value) { opts.GrainSeed = int64(v.Int()) },
+		value: func(opts lib.Options) any { return opts.GrainSeed },
+	},
+	"toneCurve": {
+		kind: js.TypeObject,
+		apply: func(opts *requestOptions, v js.Value) {
+			if !js.Global().Get("Array").Call("isArray", v).Bool() {
+				opts.ToneCurve = []float64{math.NaN()}
+				return
+			}
+			opts.ToneCurve = make([]float64, v.Length())
+			for i := range opts.ToneCurve {
+				opts.ToneCurve[i] = math.NaN()
+				if point := v.Index(i); point.Type() == js.TypeNumber {
+					opts.ToneCurve[i] = point.Float()
+				}
+			}
+		},
+		value: func(opts lib.Options) any {
+			points := make([]any, len(opts.ToneCurve))
+			for i, point := range opts.ToneCurve {
+				points[i] = point
+			}
+			return points
+		},
+	},
+	"duotoneShadow": {
+		kind:  js.TypeString,
+		apply: func(opts *requestOptions, v js.Value) { opts.DuotoneShadow = v.String() },
+		value: func(opts lib.Options) any { return opts.DuotoneShadow },
+	},
+	"duotoneHighlight": {
+		kind:  js.TypeString,
+		apply: func(opts *requestOptions, v js.Value) { opts.DuotoneHighlight = v.String() },
+		value: func(opts lib.Options) any { return opts.DuotoneHighlight },
+	},
+	"autoContrast": {
+		kind:  js.TypeBoolean,
+		apply: func(opts *requestOptions, v js.Value) { opts.AutoContrast = v.Bool() },
+		value: func(opts lib.Options) any { return opts.AutoContrast },
+	},
+	"autoLevels": {
+		kind:  js.TypeBoolean,
+		apply: func(opts *requestOptions, v js.Value) { opts.AutoLevels = v.Bool() },
+		value: func(opts lib.Options) any { return opts.AutoLevels },
+	},
+	"autoLevelsClip": {
+		kind:  js.TypeNumber,
+		apply: func(opts *requestOptions, v js.Value) { opts.AutoLevelsClip = v.Float() },
+		value: func(opts lib.Options) any { return opts.AutoLevelsClip },
+		min:   0,
+		max:   25,
+	},
+	"claheClipLimit": {
+		kind:  js.TypeNumber,
+		apply: func(opts *requestOptions, v js.Value) { opts.ClaheClipLimit = v.Float() },
+		value: func(opts lib.Options) any { return opts.ClaheClipLimit },
+		min:   0,
+		max:   64,
+	},
+	"claheTiles": {
+		kind:  js.TypeNumber,
+		apply: func(opts *requestOptions, v js.Value) { opts.ClaheTiles = v.Int() },
+		value: func(opts lib.Options) any { return opts.ClaheTiles },
+		min:   1,
+		max:   64,
+	},
+	"blur": {
+		kind:  js.TypeNumber,
+		apply: func(opts *requestOptions, v js.Value) { opts.Blur = v.Float() },
+		value: func(opts lib.Options) any { return opts.Blur },
+		min:   0,
+		max:   20,
+	},
+	"medianRadius": {
+		kind:  js.TypeNumber,
+		apply: func(opts *requestOptions, v js.Value) { opts.MedianRadius = v.Int() },
+		value: func(opts lib.Options) any { return opts.MedianRadius },
+		min:   0,
+		max:   10,
+	},
+	"pixelate": {
+		kind:  js.TypeNumber,
+		apply: func(opts *requestOptions, v js.Value) { opts.Pixelate = v.Int() },
+		value: func(opts lib.Options) any { return opts.Pixelate },
+		min:   0,
+		max:   256,
+	},
+	"unsharpRadius": {
+		kind:  js.TypeNumber,
+		apply: func(opts *requestOptions, v js.Value) { opts.UnsharpRadius = v.Float() },
+		value: func(opts lib.Options) any { return opts.UnsharpRadius },
+		min:   0,
+		max:   20,
+	},
+	"unsharpAmount": {
+		kind:  js.TypeNumber,
+		apply: func(opts *requestOptions, v js.Value) { opts.UnsharpAmount = v.Float() },
+		value: func(opts lib.Options) any { return opts.UnsharpAmount },
+		min:   0,
+		max:   5,
+	},
+	"unsharpThreshold": {
+		kind:  js.TypeNumber,
+		apply: func(opts *requestOptions, v js.Value) { opts.UnsharpThreshold = v.Float() },
+		value: func(opts lib.Options) any { return opts.UnsharpThreshold },
+		min:   0,
+		max:   255,
+	},
+	"maxColors": {
+		kind:  js.TypeNumber,
+		apply: func(opts *requestOptions, v js.Value) { opts.MaxColors = v.Int() },
+		value: func(opts lib.Options) any { return opts.MaxColors },
+		min:   0,
+		max:   256,
+	},
+	"palette": {
+		kind:   js.TypeString,
+		apply:  func(opts *requestOptions, v js.Value) { opts.Palette = v.String() },
+		value:  func(opts lib.Options) any { return opts.Palette },
+		values: lib.PaletteNames(),
+	},
+	"colorMergeTolerance": {
+		kind:  js.TypeNumber,
+		apply: func(opts *requestOptions, v js.Value) { opts.ColorMergeTolerance = v.Float() },
+		value: func(opts lib.Options) any { return opts.ColorMergeTolerance },
+		min:   0,
+		max:   100,
+	},
+	"rotate": {
+		kind:  js.TypeNumber,
+		apply: func(opts *requestOptions, v js.Value) { opts.Rotate = v.Float() },
+		value: func(opts lib.Options) any { return opts.Rotate },
+		min:   -360,
+		max:   360,
+	},
+	"rotateFill": {
+		kind:  js.TypeString,
+		apply: func(opts *requestOptions, v js.Value) { opts.RotateFill = v.String() },
+		value: func(opts lib.Options) any { return opts.RotateFill },
+	},
+	"flipHorizontal": {
+		kind:  js.TypeBoolean,
+		apply: func(opts *requestOptions, v js.Value) { opts.FlipHorizontal = v.Bool() },
+		value: func(opts lib.Options) any { return opts.FlipHorizontal },
+	},
+	"flipVertical": {
+		kind:  js.TypeBoolean,
+		apply: func(opts *requestOptions, v js.Value) { opts.FlipVertical = v.Bool() },
+		value: func(opts lib.Options) any { return opts.FlipVertical },
+	},
+	"deterministic": {
+		kind:  js.TypeBoolean,
+		apply: func(opts *requestOptions, v js.Value) { opts.Deterministic = v.Bool() },
+		value: func(opts lib.Options) any { return opts.Deterministic },
+	},
+	"outputFormat": {
+		kind:   js.TypeString,
+		apply:  func(opts *requestOptions, v js.Value) { opts.OutputFormat = v.String() },
+		value:  func(opts lib.Options) any { return opts.OutputFormat },
+		values: lib.OutputFormats,
+	},
+	"rasterScale": {
+		kind:  js.TypeNumber,
+		apply: func(opts *requestOptions, v js.Value) { opts.RasterScale = v.Float() },
+		value: func(opts lib.Options) any { return opts.RasterScale },
+		min:   1,
+		max:   4,
+	},
+	"frameDelay": {
+		kind:  js.TypeNumber,
+		apply: func(opts *requestOptions, v js.Value) { opts.FrameDelay = v.Int() },
+		value: func(opts lib.Options) any { return opts.FrameDelay },
+		min:   10,
+		max:   10000,
+	},
+	"defaultTextColor": {
+		kind:  js.TypeString,
+		apply: func(opts *requestOptions, v js.Value) { opts.DefaultTextColor = v.String() },
+		value: func(opts lib.Options) any { return opts.DefaultTextColor },
+	},
+	"border": {
+		kind:   js.TypeString,
+		apply:  func(opts *requestOptions, v js.Value) { opts.Border = v.String() },
+		value:  func(opts lib.Options) any { return opts.Border },
+		values: lib.BorderNames(),
+	},
+	"borderColor": {
+		kind:  js.TypeString,
+		apply: func(opts *requestOptions, v js.Value) { opts.BorderColor = v.String() },
+		value: func(opts lib.Options) any { return opts.BorderColor },
+	},
+	"borderWidth": {
+		kind:  js.TypeNumber,
+		apply: func(opts *requestOptions, v js.Value) { opts.BorderWidth = v.Int() },
+		value: func(opts lib.Options) any { return opts.BorderWidth },
+		min:   1,
+		max:   64,
+	},
+	"borderPadding": {
+		kind:  js.TypeNumber,
+		apply: func(opts *requestOptions, v js.Value) { opts.BorderPadding = v.Int() },
+		value: func(opts lib.Options) any { return opts.BorderPadding },
+		min:   0,
+		max:   256,
+	},
+	"trimWhitespace": {
+		kind:  js.TypeBoolean,
+		apply: func(opts *requestOptions, v js.Value) { opts.TrimWhitespace = v.Bool() },
+		value: func(opts lib.Options) any { return opts.TrimWhitespace },
+	},
+	"diffThreshold": {
+		kind:  js.TypeNumber,
+		apply: func(opts *requestOptions, v js.Value) { opts.DiffThreshold = v.Float() },
+		value: func(opts lib.Options) any { return opts.DiffThreshold },
+		min:   0,
+		max:   1,
+	},
+	"diffColor": {
+		kind:  js.TypeString,
+		apply: func(opts *requestOptions, v js.Value) { opts.DiffColor = v.String() },
+		value: func(opts lib.Options) any { return opts.DiffColor },
+	},
+	"caption": {
+		kind:  js.TypeString,
+		apply: func(opts *requestOptions, v js.Value) { opts.Caption = v.String() },
+		value: func(opts lib.Options) any { return opts.Caption },
+	},
+	"captionPosition": {
+		kind:   js.TypeString,
+		apply:  func(opts *requestOptions, v js.Value) { opts.CaptionPosition = v.String() },
+		value:  func(opts lib.Options) any { return opts.CaptionPosition },
+		values: lib.CaptionPositionNames(),
+	},
+	"captionColor": {
+		kind:  js.TypeString,
+		apply: func(opts *requestOptions, v js.Value) { opts.CaptionColor = v.String() },
+		value: func(opts lib.Options) any { return opts.CaptionColor },
+	},
+	"captionOpacity": {
+		kind:  js.TypeNumber,
+		apply: func(opts *requestOptions, v js.Value) { opts.CaptionOpacity = v.Float() },
+		value: func(opts lib.Options) any { return opts.CaptionOpacity },
+		min:   0,
+		max:   1,
+	},
+	"outlineColor": {
+		kind:  js.TypeString,
+		apply: func(opts *requestOptions, v js.Value) { opts.OutlineColor = v.String() },
+		value: func(opts lib.Options) any { return opts.OutlineColor },
+	},
+	"outlineWidth": {
+		kind:  js.TypeNumber,
+		apply: func(opts *requestOptions, v js.Value) { opts.OutlineWidth = v.Float() },
+		value: func(opts lib.Options) any { return opts.OutlineWidth },
+		min:   0,
+		max:   8,
+	},
+	"shadowColor": {
+		kind:  js.TypeString,
+		apply: func(opts *requestOptions, v js.Value) { opts.ShadowColor = v.String() },
+		value: func(opts lib.Options) any { return opts.ShadowColor },
+	},
+	"shadowOffset": {
+		kind:  js.TypeNumber,
+		apply: func(opts *requestOptions, v js.Value) { opts.ShadowOffset = v.Float() },
+		value: func(opts lib.Options) any { return opts.ShadowOffset },
+		min:   -32,
+		max:   32,
+	},
+	"shadowBlur": {
+		kind:  js.TypeNumber,
+		apply: func(opts *requestOptions, v js.Value) { opts.ShadowBlur = v.Float() },
+		value: func(opts lib.Options) any { return opts.ShadowBlur },
+		min:   0,
+		max:   32,
+	},
+	"title": {
+		kind:  js.TypeString,
+		apply: func(opts *requestOptions, v js.Value) { opts.Title = v.String() },
+		value: func(opts lib.Options) any { return opts.Title },
+	},
+	"author": {
+		kind:  js.TypeString,
+		apply: func(opts *requestOptions, v js.Value) { opts.Author = v.String() },
+		value: func(opts lib.Options) any { return opts.Author },
+	},
+	"sauce": {
+		kind:  js.TypeBoolean,
+		apply: func(opts *requestOptions, v js.Value) { opts.Sauce = v.Bool() },
+		value: func(opts lib.Options) any { return opts.Sauce },
+	},
+	"description": {
+		kind:  js.TypeString,
+		apply: func(opts *requestOptions, v js.Value) { opts.Description = v.String() },
+		value: func(opts lib.Options) any { return opts.Description },
+	},
+	"embedFont": {
+		kind: js.TypeObject,
+		apply: func(opts *requestOptions, v js.Value) {
+			opts.EmbedFont = []byte{}
+			if v.InstanceOf(js.Global().Get("Uint8Array")) {
+				opts.EmbedFont = make([]byte, v.Length())
+				js.CopyBytesToGo(opts.EmbedFont, v)
+			}
+		},
+	},
+	"backgroundGradient": {
+		kind:  js.TypeString,
+		apply: func(opts *requestOptions, v js.Value) { opts.BackgroundGradient = v.String() },
+		value: func(opts lib.Options) any { return opts.BackgroundGradient },
+	},
+	"backgroundGradientAngle": {
+		kind:  js.TypeNumber,
+		apply: func(opts *requestOptions, v js.Value) { opts.BackgroundGradientAngle = v.Float() },
+		value: func(opts lib.Options) any { return opts.BackgroundGradientAngle },
+		min:   -360,
+		max:   360,
+	},
+	"fontFamily": {
+		kind:  js.TypeString,
+		apply: func(opts *requestOptions, v js.Value) { opts.FontFamily = v.String() },
+		value: func(opts lib.Options) any { return opts.FontFamily },
+	},
+	"charWidth": {
+		kind:  js.TypeNumber,
+		apply: func(opts *requestOptions, v js.Value) { opts.CharWidth = v.Int() },
+		value: func(opts lib.Options) any { return opts.CharWidth },
+		min:   1,
+		max:   256,
+	},
+	"lineHeight": {
+		kind:  js.TypeNumber,
+		apply: func(opts *requestOptions, v js.Value) { opts.LineHeight = v.Int() },
+		value: func(opts lib.Options) any { return opts.LineHeight },
+		min:   1,
+		max:   256,
+	},
+	"fontSize": {
+		kind:  js.TypeNumber,
+		apply: func(opts *requestOptions, v js.Value) { opts.FontSize = v.Int() },
+		value: func(opts lib.Options) any { return opts.FontSize },
+		min:   1,
+		max:   256,
+	},
+	"letterSpacing": {
+		kind:  js.TypeNumber,
+		apply: func(opts *requestOptions, v js.Value) { opts.LetterSpacing = v.Float() },
+		value: func(opts lib.Options) any { return opts.LetterSpacing },
+		min:   0.5,
+		max:   4,
+	},
+	"lineSpacing": {
+		kind:  js.TypeNumber,
+		apply: func(opts *requestOptions, v js.Value) { opts.LineSpacing = v.Float() },
+		value: func(opts lib.Options) any { return opts.LineSpacing },
+		min:   0.5,
+		max:   4,
+	},
+	"paddingTop": {
+		kind:  js.TypeNumber,
+		apply: func(opts *requestOptions, v js.Value) { opts.PaddingTop = v.Int() },
+		value: func(opts lib.Options) any { return opts.PaddingTop },
+		min:   -256,
+		max:   256,
+	},
+	"paddingBottom": {
+		kind:  js.TypeNumber,
+		apply: func(opts *requestOptions, v js.Value) { opts.PaddingBottom = v.Int() },
+		value: func(opts lib.Options) any { return opts.PaddingBottom },
+		min:   -256,
+		max:   256,
+	},
+	"paddingLeft": {
+		kind:  js.TypeNumber,
+		apply: func(opts *requestOptions, v js.Value) { opts.PaddingLeft = v.Int() },
+		value: func(opts lib.Options) any { return opts.PaddingLeft },
+		min:   -256,
+		max:   256,
+	},
+	"paddingRight": {
+		kind:  js.TypeNumber,
+		apply: func(opts *requestOptions, v js.Value) { opts.PaddingRight = v.Int() },
+		value: func(opts lib.Options) any { return opts.PaddingRight },
+		min:   -256,
+		max:   256,
+	},
+	"detailed": {
+		kind:  js.TypeBoolean,
+		apply: func(opts *requestOptions, v js.Value) { opts.detailed = v.Bool() },
+	},
+	"onProgress": {
+		kind: js.TypeFunction,
+		apply: func(opts *requestOptions, v js.Value) {
+			opts.Progress = func(stage string, percent float64) {
+				v.Invoke(map[string]any{"stage": stage, "percent": percent})
+			}
+		},
+	},
+	"charSelector": {
+		kind:  js.TypeFunction,
+		apply: func(opts *requestOptions, v js.Value) { opts.CharSelector = jsCharSelector(v) },
+	},
+	"onChunk": {
+		kind: js.TypeFunction,
+		apply: func(opts *requestOptions, v js.Value) {
+			opts.OnChunk = func(chunk []byte) error {
+				v.Invoke(bytesToJS(chunk))
+				return nil
+			}
+		},
+	},
+	"onLine": {
+		kind: js.TypeFunction,
+		apply: func(opts *requestOptions, v js.Value) {
+			opts.OnLine = func(row int, data []byte) error {
+				v.Invoke(row, string(data))
+				return nil
+			}
+		},
+	},
+}
+
+func parseOptions(args []js.Value) (requestOptions, error) {
+	switch len(args) {
+	case 0:
+		return requestOptions{Options: lib.DefaultOptions()}, nil
+	case 1:
+		return parseOptionsObject(args[0])
+	case 7:
+		return parseOptionArgs(args), nil
+	default:
+		return requestOptions{}, lib.NewError(lib.CodeBadOption, "expected an options object or 7 positional options, but got %d arguments", len(args))
+	}
+}
+
+func parseOptionsObject(obj js.Value) (requestOptions, error) {
+	opts := requestOptions{Options: lib.DefaultOptions()}
+	if obj.IsNull() || obj.IsUndefined() {
+		return opts, nil
+	}
+	if obj.Type() != js.TypeObject {
+		return requestOptions{}, lib.NewError(lib.CodeBadOption, "options must be an object, got %s", obj.Type())
+	}
+
+	maxDimension := lib.CurrentLimits().MaxASCIIDimension
+	if v := obj.Get("maxASCIIDimension"); v.Type() == js.TypeNumber && v.Int() > 0 {
+		maxDimension = v.Int()
+	}
+
+	keys := js.Global().Get("Object").Call("keys", obj)
+	for i := 0; i < keys.Length(); i++ {
+		key := keys.Index(i).String()
+		field, ok := optionFields[key]
+		if !ok {
+			return requestOptions{}, lib.NewOptionError(key, "unknown option %q (valid options: %s)", key, strings.Join(optionNames(), ", "))
+		}
+
+		value := obj.Get(key)
+		if value.IsUndefined() {
+			continue
+		}
+		if value.Type() != field.kind {
+			return requestOptions{}, lib.NewOptionError(key, "option %q must be a %s, got %s", key, field.kind, value.Type())
+		}
+		if min, max := field.bounds(maxDimension); field.kind == js.TypeNumber && (min != 0 || max != 0) && (value.Float() < min || value.Float() > max) {
+			return requestOptions{}, lib.NewOptionError(key, "option %q must be between %g and %g, got %g", key, min, max, value.Float())
+		}
+		field.apply(&opts, value)
+	}
+
+	return opts, nil
+}
+
+func optionNames() []string {
+	names := make([]string, 0, len(optionFields))
+	for name := range optionFields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,35 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"image-to-ascii-art/lib"
+	"syscall/js"
+)
+
+const defaultExtractColors = 5
+
+func extractPaletteHandler(args []js.Value) (any, error) {
+	if len(args) == 0 {
+		return nil, lib.NewError(lib.CodeBadInput, "expected imageData as the first argument")
+	}
+
+	imageDataGo, err := readImageData(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	n := defaultExtractColors
+	if len(args) > 1 && !args[1].IsUndefined() && !args[1].IsNull() {
+		if args[1].Type() != js.TypeNumber {
+			return nil, lib.NewError(lib.CodeBadInput, "color count must be a number, got %s", args[1].Type())
+		}
+		n = args[1].Int()
+	}
+
+	colors, err := lib.ExtractPalette(imageDataGo, n)
+	if err != nil {
+		return nil, err
+	}
+	return stringsToJS(colors), nil
+}
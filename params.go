@@ -0,0 +1,128 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"encoding/base64"
+	"image-to-ascii-art/lib"
+	"strings"
+	"syscall/js"
+)
+
+func validateImageParams(args []js.Value) ([]byte, requestOptions, error) {
+	if len(args) == 0 {
+		return nil, requestOptions{}, lib.NewError(lib.CodeBadInput, "expected imageData as the first argument")
+	}
+
+	imageDataGo, err := readImageData(args[0])
+	if err != nil {
+		return nil, requestOptions{}, err
+	}
+
+	opts, err := parseOptions(args[1:])
+	if err != nil {
+		return nil, requestOptions{}, err
+	}
+
+	return imageDataGo, opts, nil
+}
+
+func readImageData(imageDataJS js.Value) ([]byte, error) {
+	if imageDataJS.IsNull() || imageDataJS.IsUndefined() {
+		return nil, lib.NewError(lib.CodeBadInput, "imageData is null or undefined")
+	}
+
+	if imageDataJS.Type() == js.TypeString {
+		return decodeDataURL(imageDataJS.String())
+	}
+	if imageDataJS.Type() == js.TypeNumber {
+		return lookupInputBuffer(imageDataJS)
+	}
+
+	imageDataLength := imageDataJS.Get("length")
+	if imageDataLength.IsNull() || imageDataLength.IsUndefined() {
+		return nil, lib.NewError(lib.CodeBadInput, "imageData has no length property")
+	}
+
+	length := imageDataLength.Int()
+	if length <= 0 {
+		return nil, lib.NewError(lib.CodeBadInput, "imageData length is invalid: %d", length)
+	}
+
+	imageDataGo := make([]byte, length)
+	js.CopyBytesToGo(imageDataGo, imageDataJS)
+	return imageDataGo, nil
+}
+
+func validateImageSourceParams(args []js.Value) ([]byte, int, int, requestOptions, error) {
+	if len(args) == 0 {
+		return nil, 0, 0, requestOptions{}, lib.NewError(lib.CodeBadInput, "expected an image source as the first argument")
+	}
+
+	opts, err := parseOptions(args[1:])
+	if err != nil {
+		return nil, 0, 0, requestOptions{}, err
+	}
+
+	pixels, width, height, err := readImageSource(args[0])
+	if err != nil {
+		return nil, 0, 0, requestOptions{}, err
+	}
+
+	return pixels, width, height, opts, nil
+}
+
+func parseOptionArgs(args []js.Value) requestOptions {
+	return requestOptions{Options: lib.Options{
+		TargetWidth:           args[0].Int(),
+		Brightness:            args[1].Float(),
+		Contrast:              args[2].Float(),
+		Sharpen:               args[3].Float(),
+		BackgroundColor:       args[4].String(),
+		TransparencyColor:     args[5].String(),
+		TransparencyThreshold: args[6].Float(),
+		MaxProcessDimension:   lib.DefaultMaxProcessDimension,
+		PaddingTop:            lib.DefaultPaddingTop,
+		PaddingBottom:         lib.DefaultPaddingBottom,
+		PaddingLeft:           lib.DefaultPaddingLeft,
+		PaddingRight:          lib.DefaultPaddingRight,
+	}}
+}
+
+func decodeDataURL(dataURL string) ([]byte, error) {
+	payload := strings.TrimSpace(dataURL)
+	if strings.HasPrefix(payload, "data:") {
+		header, data, found := strings.Cut(payload, ",")
+		if !found {
+			return nil, lib.NewError(lib.CodeBadInput, "malformed data URL: missing ',' separator")
+		}
+		if !strings.HasSuffix(header, ";base64") {
+			return nil, lib.NewError(lib.CodeBadInput, "unsupported data URL encoding: only base64 data URLs are supported")
+		}
+		payload = data
+	}
+	if payload == "" {
+		return nil, lib.NewError(lib.CodeBadInput, "imageData string is empty")
+	}
+
+	payload = strings.Map(func(r rune) rune {
+		if r == ' ' || r == '\n' || r == '\r' || r == '\t' {
+			return -1
+		}
+		return r
+	}, payload)
+
+	encoding := base64.StdEncoding
+	if strings.ContainsAny(payload, "-_") {
+		encoding = base64.URLEncoding
+	}
+	if !strings.HasSuffix(payload, "=") && len(payload)%4 != 0 {
+		encoding = encoding.WithPadding(base64.NoPadding)
+	}
+
+	imageDataGo, err := encoding.DecodeString(payload)
+	if err != nil {
+		return nil, lib.NewError(lib.CodeDecode, "failed to decode base64 image data: %w", err)
+	}
+	return imageDataGo, nil
+}
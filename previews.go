@@ -0,0 +1,56 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"fmt"
+	"image-to-ascii-art/lib"
+	"syscall/js"
+)
+
+func processPreviewsHandler(args []js.Value) (any, error) {
+	if len(args) < 2 {
+		return nil, lib.NewError(lib.CodeBadInput, "expected imageData and an array of target widths")
+	}
+
+	imageDataGo, err := readImageData(args[0])
+	if err != nil {
+		return nil, err
+	}
+	widths, err := readWidths(args[1])
+	if err != nil {
+		return nil, err
+	}
+	opts, err := parseOptions(args[2:])
+	if err != nil {
+		return nil, err
+	}
+	logOptions(opts.Options)
+
+	results, err := lib.ProcessImageWidths(imageDataGo, widths, opts.Options)
+	if err != nil {
+		return nil, fmt.Errorf("error processing image: %w", err)
+	}
+
+	output := make([]any, len(results))
+	for i, result := range results {
+		output[i] = resultToJS(result, opts.detailed)
+	}
+	return js.ValueOf(output), nil
+}
+
+func readWidths(widthsJS js.Value) ([]int, error) {
+	if !js.Global().Get("Array").Call("isArray", widthsJS).Bool() {
+		return nil, lib.NewError(lib.CodeBadInput, "target widths must be an array, got %s", widthsJS.Type())
+	}
+
+	widths := make([]int, widthsJS.Length())
+	for i := range widths {
+		width := widthsJS.Index(i)
+		if width.Type() != js.TypeNumber {
+			return nil, lib.NewError(lib.CodeBadInput, "target width at index %d must be a number, got %s", i, width.Type())
+		}
+		widths[i] = width.Int()
+	}
+	return widths, nil
+}
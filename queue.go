@@ -0,0 +1,75 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"image-to-ascii-art/lib"
+	"sync"
+	"syscall/js"
+)
+
+const (
+	defaultMaxJobs = 2
+	maxJobsLimit   = 16
+)
+
+var jobs = &jobQueue{limit: defaultMaxJobs}
+
+type jobQueue struct {
+	mu      sync.Mutex
+	limit   int
+	running int
+	waiting []chan struct{}
+}
+
+func (q *jobQueue) acquire() {
+	ready := make(chan struct{})
+	q.mu.Lock()
+	q.waiting = append(q.waiting, ready)
+	q.start()
+	q.mu.Unlock()
+	<-ready
+}
+
+func (q *jobQueue) release() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.running--
+	q.start()
+}
+
+func (q *jobQueue) setLimit(limit int) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.limit = limit
+	q.start()
+}
+
+func (q *jobQueue) start() {
+	for q.running < q.limit && len(q.waiting) > 0 {
+		close(q.waiting[0])
+		q.waiting = q.waiting[1:]
+		q.running++
+	}
+}
+
+func queued(fn func(args []js.Value) (any, error)) func(args []js.Value) (any, error) {
+	return func(args []js.Value) (any, error) {
+		jobs.acquire()
+		defer jobs.release()
+		return fn(args)
+	}
+}
+
+func setMaxConcurrentJobsHandler(args []js.Value) (any, error) {
+	if len(args) != 1 || args[0].Type() != js.TypeNumber {
+		return nil, lib.NewError(lib.CodeBadInput, "expected the maximum number of concurrent jobs as the only argument")
+	}
+
+	limit := args[0].Int()
+	if limit < 1 || limit > maxJobsLimit {
+		return nil, lib.NewOptionError("maxConcurrentJobs", "maxConcurrentJobs must be between 1 and %d, got %d", maxJobsLimit, limit)
+	}
+	jobs.setLimit(limit)
+	return limit, nil
+}
@@ -0,0 +1,94 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"fmt"
+	"image-to-ascii-art/lib"
+	"sync"
+	"syscall/js"
+)
+
+var (
+	sessionsMu sync.Mutex
+	sessions   = make(map[int]*lib.Session)
+	nextHandle = 1
+)
+
+func createSessionHandler(args []js.Value) (any, error) {
+	if len(args) != 1 {
+		return nil, lib.NewError(lib.CodeBadInput, "expected 1 argument, but got %d", len(args))
+	}
+
+	imageDataGo, err := readImageData(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := lib.NewSession(imageDataGo)
+	if err != nil {
+		return nil, fmt.Errorf("error creating session: %w", err)
+	}
+
+	sessionsMu.Lock()
+	defer sessionsMu.Unlock()
+	handle := nextHandle
+	nextHandle++
+	sessions[handle] = session
+
+	lib.Logf(lib.LevelDebug, "Image session %d created", handle)
+	return handle, nil
+}
+
+func renderSessionHandler(args []js.Value) (any, error) {
+	if len(args) == 0 {
+		return nil, lib.NewError(lib.CodeBadInput, "expected a session handle as the first argument")
+	}
+
+	session, err := lookupSession(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	opts, err := parseOptions(args[1:])
+	if err != nil {
+		return nil, err
+	}
+	logOptions(opts.Options)
+
+	result, err := session.Render(opts.Options)
+	if err != nil {
+		return nil, fmt.Errorf("error rendering session: %w", err)
+	}
+	return resultToJS(result, opts.detailed), nil
+}
+
+func releaseSession(this js.Value, args []js.Value) any {
+	if len(args) != 1 || args[0].Type() != js.TypeNumber {
+		return false
+	}
+
+	sessionsMu.Lock()
+	defer sessionsMu.Unlock()
+	handle := args[0].Int()
+	if _, ok := sessions[handle]; !ok {
+		return false
+	}
+	delete(sessions, handle)
+	return true
+}
+
+func lookupSession(handleJS js.Value) (*lib.Session, error) {
+	if handleJS.Type() != js.TypeNumber {
+		return nil, lib.NewError(lib.CodeBadInput, "session handle must be a number")
+	}
+
+	sessionsMu.Lock()
+	defer sessionsMu.Unlock()
+	handle := handleJS.Int()
+	session, ok := sessions[handle]
+	if !ok {
+		return nil, lib.NewError(lib.CodeBadInput, "unknown or released session handle: %d", handle)
+	}
+	return session, nil
+}
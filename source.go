@@ -0,0 +1,44 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"image-to-ascii-art/lib"
+	"syscall/js"
+)
+
+func readImageSource(source js.Value) ([]byte, int, int, error) {
+	if source.IsNull() || source.IsUndefined() {
+		return nil, 0, 0, lib.NewError(lib.CodeBadInput, "image source is null or undefined")
+	}
+
+	widthJS, heightJS := source.Get("width"), source.Get("height")
+	if widthJS.Type() != js.TypeNumber || heightJS.Type() != js.TypeNumber {
+		return nil, 0, 0, lib.NewError(lib.CodeBadInput, "image source must be an ImageBitmap or OffscreenCanvas")
+	}
+	width, height := widthJS.Int(), heightJS.Int()
+	if width <= 0 || height <= 0 {
+		return nil, 0, 0, lib.NewError(lib.CodeBadInput, "invalid image source dimensions: %dx%d", width, height)
+	}
+	if err := lib.ValidateImagePixels(width, height); err != nil {
+		return nil, 0, 0, err
+	}
+
+	offscreenCanvas := js.Global().Get("OffscreenCanvas")
+	if offscreenCanvas.IsUndefined() {
+		return nil, 0, 0, lib.NewError(lib.CodeBadInput, "OffscreenCanvas is not supported in this environment")
+	}
+
+	canvas := offscreenCanvas.New(width, height)
+	ctx := canvas.Call("getContext", "2d")
+	if ctx.IsNull() {
+		return nil, 0, 0, lib.NewError(lib.CodeBadInput, "failed to get 2d context from OffscreenCanvas")
+	}
+	ctx.Call("drawImage", source, 0, 0)
+
+	data := ctx.Call("getImageData", 0, 0, width, height).Get("data")
+	pixels := make([]byte, data.Get("length").Int())
+	js.CopyBytesToGo(pixels, data)
+
+	return pixels, width, height, nil
+}
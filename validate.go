@@ -0,0 +1,47 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"image-to-ascii-art/lib"
+	"syscall/js"
+)
+
+func validateOptionsHandler(args []js.Value) (any, error) {
+	opts, err := parseOptions(args[:min(len(args), 1)])
+	if err != nil {
+		return nil, err
+	}
+
+	var srcWidth, srcHeight int
+	if len(args) > 1 && !args[1].IsUndefined() {
+		if len(args) < 3 || args[1].Type() != js.TypeNumber || args[2].Type() != js.TypeNumber {
+			return nil, lib.NewError(lib.CodeBadInput, "image width and height must be numbers")
+		}
+		srcWidth, srcHeight = args[1].Int(), args[2].Int()
+	}
+
+	report, err := lib.ResolveOptions(opts.Options, srcWidth, srcHeight)
+	if err != nil {
+		return nil, err
+	}
+
+	effective := make(map[string]any, len(optionFields))
+	for name, field := range optionFields {
+		if field.value != nil {
+			effective[name] = field.value(report.Options)
+		}
+	}
+	result := map[string]any{
+		"options":        effective,
+		"asciiWidth":     report.ASCIIWidth,
+		"asciiHeight":    nil,
+		"estimatedBytes": nil,
+		"warnings":       warningsToJS(report.Warnings),
+	}
+	if report.ASCIIHeight > 0 {
+		result["asciiHeight"] = report.ASCIIHeight
+		result["estimatedBytes"] = report.EstimatedSize
+	}
+	return js.ValueOf(result), nil
+}
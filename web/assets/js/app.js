@@ -55,7 +55,7 @@ document.addEventListener('DOMContentLoaded', () => {
         setTimeout(async () => {
             try {
                 const params = {
-                    width: parseInt(DOM.sliders.width.value, 10),
+                    targetWidth: parseInt(DOM.sliders.width.value, 10),
                     brightness: parseFloat(DOM.sliders.brightness.value),
                     contrast: parseFloat(DOM.sliders.contrast.value),
                     sharpen: parseFloat(DOM.sliders.sharpen.value),
@@ -64,7 +64,7 @@ document.addEventListener('DOMContentLoaded', () => {
                     transparencyThreshold: parseFloat(DOM.sliders.transparencyThreshold.value),
                 };
 
-                const svgData = await window.processImageGo(originalImageData, ...Object.values(params));
+                const svgData = await window.processImageGo(originalImageData, params);
                 if (!svgData) throw new Error('Generated SVG data is empty.');
 
                 if (lastSvgUrl) URL.revokeObjectURL(lastSvgUrl);
@@ -75,8 +75,8 @@ document.addEventListener('DOMContentLoaded', () => {
             } catch (error) {
                 console.error('Processing error:', error);
                 let msg = `Processing failed: ${error.message}`;
-                if (error.message.includes('terlalu besar')) msg = 'Output too large. Try reducing the ASCII width.';
-                else if (error.message.includes('gagal decode')) msg = 'Failed to decode image. Please check the file.';
+                if (error.code === 'ERR_TOO_LARGE') msg = 'Output too large. Try reducing the ASCII width.';
+                else if (error.code === 'ERR_DECODE') msg = 'Failed to decode image. Please check the file.';
                 showMessage(msg, 'error');
             } finally {
                 DOM.loadingOverlay.classList.remove('active');